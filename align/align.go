@@ -0,0 +1,87 @@
+package align
+
+import (
+	"github.com/muesli/reflow/padding"
+)
+
+// Position describes where the content of a line is placed within the width.
+type Position int
+
+const (
+	// Left places the content at the start of the line.
+	Left Position = iota
+	// Center places the content in the middle of the line. If the remaining
+	// space can't be split evenly, the extra cell goes to the right.
+	Center
+	// Right places the content at the end of the line.
+	Right
+)
+
+// Align aligns each line of a block of text within a field of the given width.
+// Lines wider than the width are left untouched.
+type Align struct {
+	Width int
+	To    Position
+
+	pw *padding.Writer
+}
+
+// Bytes is shorthand for declaring a new Align instance, used to immediately
+// align a byte slice.
+func Bytes(b []byte, width int, to Position) []byte {
+	a := &Align{Width: width, To: to}
+	_, _ = a.Write(b)
+	_ = a.Close()
+
+	return a.Bytes()
+}
+
+// String is shorthand for declaring a new Align instance, used to immediately
+// align a string.
+func String(s string, width int, to Position) string {
+	return string(Bytes([]byte(s), width, to))
+}
+
+// Write is used to write content to the align buffer.
+func (a *Align) Write(b []byte) (int, error) {
+	if a.pw == nil {
+		var width uint
+		if a.Width > 0 {
+			width = uint(a.Width)
+		}
+
+		a.pw = padding.NewWriter(width, nil)
+		switch a.To {
+		case Center:
+			a.pw.Align = padding.AlignCenter
+		case Right:
+			a.pw.Align = padding.AlignRight
+		default:
+			a.pw.Align = padding.AlignLeft
+		}
+	}
+
+	return a.pw.Write(b)
+}
+
+// Close will finish the align operation. Always call it before trying to
+// retrieve the final result.
+func (a *Align) Close() error {
+	if a.pw == nil {
+		return nil
+	}
+	return a.pw.Close()
+}
+
+// Bytes returns the aligned result as a byte slice.
+func (a *Align) Bytes() []byte {
+	if a.pw == nil {
+		return nil
+	}
+	return a.pw.Bytes()
+}
+
+// String returns the aligned result as a string.
+func (a *Align) String() string {
+	return string(a.Bytes())
+}
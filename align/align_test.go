@@ -0,0 +1,92 @@
+package align
+
+import (
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Width    int
+		To       Position
+	}{
+		// No-op, should pass through:
+		{
+			"foo\nbar",
+			"foo\nbar",
+			0,
+			Center,
+		},
+		// Left alignment:
+		{
+			"foo\nfoobar",
+			"foo     \nfoobar  ",
+			8,
+			Left,
+		},
+		// Center alignment:
+		{
+			"foo\nfoobar",
+			"  foo   \n foobar ",
+			8,
+			Center,
+		},
+		// Right alignment:
+		{
+			"foo\nfoobar",
+			"     foo\n  foobar",
+			8,
+			Right,
+		},
+		// Lines wider than the width are untouched:
+		{
+			"foo\nfoobarbaz",
+			"   foo\nfoobarbaz",
+			6,
+			Right,
+		},
+		// ANSI sequences are zero-width and preserved:
+		{
+			"\x1B[31mfoo\x1B[0m\nfoobar",
+			" \x1B[31mfoo\x1B[0m  \nfoobar",
+			6,
+			Center,
+		},
+		// Double-width runes:
+		{
+			"你好\nfoobar",
+			"  你好\nfoobar",
+			6,
+			Right,
+		},
+	}
+
+	for i, tc := range tt {
+		a := &Align{Width: tc.Width, To: tc.To}
+
+		_, err := a.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		if err := a.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if a.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, a.String())
+		}
+	}
+}
+
+func TestAlignString(t *testing.T) {
+	t.Parallel()
+
+	actual := String("foo", 5, Right)
+	expected := "  foo"
+	if actual != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, actual)
+	}
+}
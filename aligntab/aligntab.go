@@ -0,0 +1,101 @@
+package aligntab
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// Writer aligns tab-separated columns across lines, like text/tabwriter, but
+// measures cells by their printable width, so escape sequences don't throw off
+// the alignment. Every cell followed by a tab is padded to the width of the
+// widest cell of its column, while the last cell of a line is left as it is.
+// The whole input is buffered until Flush, as any line may widen a column.
+type Writer struct {
+	// Padding is the amount of cells added between columns.
+	Padding uint
+
+	in  bytes.Buffer
+	buf bytes.Buffer
+}
+
+// NewWriter returns a new aligntab-writer, separating columns by the given
+// padding.
+func NewWriter(padding uint) *Writer {
+	return &Writer{
+		Padding: padding,
+	}
+}
+
+// Bytes is shorthand for declaring a new default aligntab-writer instance,
+// used to immediately align the columns of a byte slice.
+func Bytes(b []byte, padding uint) []byte {
+	f := NewWriter(padding)
+	_, _ = f.Write(b)
+	_ = f.Flush()
+
+	return f.Bytes()
+}
+
+// String is shorthand for declaring a new default aligntab-writer instance,
+// used to immediately align the columns of a string.
+func String(s string, padding uint) string {
+	return string(Bytes([]byte(s), padding))
+}
+
+// Write buffers content until it gets aligned by Flush.
+func (w *Writer) Write(b []byte) (int, error) {
+	return w.in.Write(b)
+}
+
+// Flush aligns the columns of all content written so far. Always call it
+// before trying to retrieve the final result.
+func (w *Writer) Flush() error {
+	var rows [][]string
+	var widths []int
+	for _, l := range strings.Split(w.in.String(), "\n") {
+		cells := strings.Split(l, "\t")
+		for i, c := range cells[:len(cells)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := ansi.PrintableRuneWidth(c); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	for i, cells := range rows {
+		if i > 0 {
+			_ = w.buf.WriteByte('\n')
+		}
+		for j, c := range cells {
+			_, _ = w.buf.WriteString(c)
+			if j < len(cells)-1 {
+				// pad the cell to the width of its column
+				n := widths[j] - ansi.PrintableRuneWidth(c) + int(w.Padding)
+				_, _ = w.buf.WriteString(strings.Repeat(" ", n))
+			}
+		}
+	}
+
+	w.in.Reset()
+	return nil
+}
+
+// Close will finish the alignment, like Flush.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// Bytes returns the aligned result as a byte slice.
+func (w *Writer) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// String returns the aligned result as a string.
+func (w *Writer) String() string {
+	return w.buf.String()
+}
@@ -0,0 +1,91 @@
+package aligntab
+
+import (
+	"testing"
+)
+
+func TestAlignTab(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Padding  uint
+	}{
+		{
+			"",
+			"",
+			2,
+		},
+		{
+			"no tabs",
+			"no tabs",
+			2,
+		},
+		// Multiple rows and columns:
+		{
+			"a\tbb\tc\naaa\tb\tccc\n",
+			"a    bb  c\naaa  b   ccc\n",
+			2,
+		},
+		{
+			"name\tsize\nfoo.go\t12\nx\t1024",
+			"name   size\nfoo.go 12\nx      1024",
+			1,
+		},
+		// Styled cells:
+		{
+			"\x1B[31mred\x1B[0m\tx\nlonger\ty",
+			"\x1B[31mred\x1B[0m     x\nlonger  y",
+			2,
+		},
+		{
+			"\x1B[1mname\x1B[0m\t\x1B[1msize\x1B[0m\tdate\nfoo\t\x1B[32m12\x1B[0m\ttoday",
+			"\x1B[1mname\x1B[0m \x1B[1msize\x1B[0m date\nfoo  \x1B[32m12\x1B[0m   today",
+			1,
+		},
+		// Wide characters:
+		{
+			"你好\tx\nfoo\ty",
+			"你好  x\nfoo   y",
+			2,
+		},
+		// Blank cells and lines:
+		{
+			"\tx\nfoo\ty",
+			"     x\nfoo  y",
+			2,
+		},
+		{
+			"a\tb\n\nccc\td\te",
+			"a    b\n\nccc  d  e",
+			2,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Padding)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestAlignTabWriter(t *testing.T) {
+	t.Parallel()
+
+	f := NewWriter(1)
+	for _, s := range []string{"foo\tb", "ar\n", "\x1B[31mfoobar\x1B[0m\tbaz"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+
+	exp := "foo    bar\n\x1B[31mfoobar\x1B[0m baz"
+	if f.String() != exp {
+		t.Errorf("expected:\n\n`%q`\n\nActual Output:\n\n`%q`", exp, f.String())
+	}
+}
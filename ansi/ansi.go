@@ -5,3 +5,82 @@ const Marker = '\x1B'
 func IsTerminator(c rune) bool {
 	return (c >= 0x40 && c <= 0x5a) || (c >= 0x61 && c <= 0x7a)
 }
+
+// seqState is the state of the escape sequence detection.
+type seqState int
+
+const (
+	stateText      seqState = iota // printable content
+	stateEscape                    // after the marker
+	stateIntermed                  // within a non-CSI sequence's intermediate bytes
+	stateCSI                       // within a control sequence
+	stateString                    // within an OSC, DCS, SOS, PM or APC string
+	stateStringEsc                 // after a marker within a string
+)
+
+// next returns the state following c, and whether c is part of an escape
+// sequence.
+func (s seqState) next(c rune) (seqState, bool) {
+	switch s {
+	case stateEscape:
+		switch {
+		case c == '[':
+			return stateCSI, true
+		case c == ']' || c == 'P' || c == 'X' || c == '^' || c == '_':
+			return stateString, true
+		case c >= 0x20 && c <= 0x2f:
+			return stateIntermed, true
+		}
+		// single character sequence
+		return stateText, true
+
+	case stateIntermed:
+		if c >= 0x20 && c <= 0x2f {
+			return stateIntermed, true
+		}
+		return stateText, true
+
+	case stateCSI:
+		if c >= 0x40 && c <= 0x7e {
+			return stateText, true
+		}
+		return stateCSI, true
+
+	case stateString:
+		switch c {
+		case '\a':
+			return stateText, true
+		case Marker:
+			return stateStringEsc, true
+		}
+		return stateString, true
+
+	case stateStringEsc:
+		if c == '\\' {
+			// string terminator
+			return stateText, true
+		}
+		// the string got interrupted by another sequence
+		return stateEscape.next(c)
+	}
+
+	if c == Marker {
+		return stateEscape, true
+	}
+	return stateText, false
+}
+
+// SeqState tracks whether a stream of runes is within an escape sequence, for
+// writers processing their input rune by rune. The zero value is outside of
+// any sequence.
+type SeqState struct {
+	state seqState
+}
+
+// Next advances the state by c and reports whether c is part of an escape
+// sequence.
+func (s *SeqState) Next(c rune) bool {
+	var seq bool
+	s.state, seq = s.state.next(c)
+	return seq
+}
@@ -2,39 +2,199 @@ package ansi
 
 import (
 	"bytes"
+	"io"
+	"strings"
 
 	"github.com/mattn/go-runewidth"
 )
 
-// Buffer is a buffer aware of ANSI escape sequences.
+// Buffer is a buffer aware of ANSI escape sequences. Its printable widths are
+// measured on demand, so it can be drained and filled like a bytes.Buffer,
+// e.g. by its WriteTo and ReadFrom methods.
 type Buffer struct {
 	bytes.Buffer
 }
 
+var (
+	_ io.WriterTo   = &Buffer{}
+	_ io.ReaderFrom = &Buffer{}
+)
+
+// Clone returns an independent copy of the buffer, so writes to either of
+// them don't affect the other.
+func (w Buffer) Clone() Buffer {
+	var b Buffer
+	_, _ = b.Write(w.Bytes())
+	return b
+}
+
 // PrintableRuneWidth returns the cell width of all printable runes in the
 // buffer.
 func (w Buffer) PrintableRuneWidth() int {
 	return PrintableRuneWidth(w.String())
 }
 
+// PrintableRuneWidthFunc returns the cell width of all printable runes in the
+// buffer, measuring each of them with the given function.
+func (w Buffer) PrintableRuneWidthFunc(runeWidth func(rune) int) int {
+	return PrintableRuneWidthFunc(w.String(), runeWidth)
+}
+
+// PrintableGraphemeWidth returns the cell width of all printable grapheme
+// clusters in the buffer.
+func (w Buffer) PrintableGraphemeWidth() int {
+	return PrintableGraphemeWidth(w.String())
+}
+
+// TrimmedRuneWidth returns the cell width of all printable runes in the
+// buffer, ignoring trailing spaces and tabs.
+func (w Buffer) TrimmedRuneWidth() int {
+	return TrimmedRuneWidth(w.String())
+}
+
 // PrintableRuneWidth returns the cell width of the given string.
 func PrintableRuneWidth(s string) int {
-	var n int
-	var ansi bool
+	return PrintableRuneWidthFunc(s, runewidth.RuneWidth)
+}
+
+// PrintableRuneWidthFunc returns the cell width of the given string, measuring
+// each printable rune with the given function, e.g. the RuneWidth method of a
+// runewidth.Condition.
+func PrintableRuneWidthFunc(s string, runeWidth func(rune) int) int {
+	var n widthCounter
+	var state seqState
+	var seq bool
 
 	for _, c := range s {
-		if c == Marker {
+		state, seq = state.next(c)
+		if seq {
 			// ANSI escape sequence
-			ansi = true
-		} else if ansi {
-			if IsTerminator(c) {
-				// ANSI sequence terminated
-				ansi = false
-			}
-		} else {
-			n += runewidth.RuneWidth(c)
+			continue
 		}
+		n.add(c, runeWidth)
+	}
+
+	return n.n
+}
+
+// TrimmedRuneWidth returns the cell width of the given string, ignoring
+// trailing spaces and tabs.
+func TrimmedRuneWidth(s string) int {
+	var n, trailing int
+	var width widthCounter
+	var state seqState
+	var seq bool
+
+	for _, c := range s {
+		state, seq = state.next(c)
+		if seq {
+			// ANSI escape sequence
+			continue
+		}
+		if c == ' ' || c == '\t' {
+			trailing += width.add(c, runewidth.RuneWidth)
+			continue
+		}
+		n += trailing + width.add(c, runewidth.RuneWidth)
+		trailing = 0
 	}
 
 	return n
 }
+
+// PrintableGraphemeWidth returns the cell width of the given string, measured
+// per grapheme cluster instead of per rune. This way combining characters and
+// emoji sequences count as a single glyph.
+func PrintableGraphemeWidth(s string) int {
+	var b strings.Builder
+	var state seqState
+	var seq bool
+
+	for _, c := range s {
+		state, seq = state.next(c)
+		if seq {
+			// ANSI escape sequence
+			continue
+		}
+		_, _ = b.WriteRune(c)
+	}
+
+	var n int
+	Graphemes(b.String(), runewidth.RuneWidth, func(_ string, _ []rune, width int) {
+		n += width
+	})
+	return n
+}
+
+// Cell is a printable rune of a styled text, along with its cell width and the
+// SGR attributes active at its position.
+type Cell struct {
+	Rune  rune
+	Width int
+	Style Style
+}
+
+// Cells decodes the buffer into its printable runes, each carrying the style
+// active at its position. Other escape sequences than SGR ones are dropped.
+func (w Buffer) Cells() []Cell {
+	var cells []Cell
+	var style Style
+	var width widthCounter
+
+	Parse(w.Bytes(), func(seq, text []byte) {
+		if len(seq) > 2 && seq[1] == '[' && seq[len(seq)-1] == 'm' {
+			style.Apply(string(seq[2 : len(seq)-1]))
+		}
+		for _, c := range string(text) {
+			d := width.add(c, runewidth.RuneWidth)
+			if c == vs15 || c == vs16 {
+				// changes the width of the preceding rune
+				if len(cells) > 0 {
+					cells[len(cells)-1].Width += d
+				}
+				d = 0
+			}
+			cells = append(cells, Cell{
+				Rune:  c,
+				Width: d,
+				Style: style,
+			})
+		}
+	})
+
+	return cells
+}
+
+// TruncateWidth cuts the content of the buffer after the given printable cell
+// width, keeping all escape sequences before the cut. Double-width runes
+// straddling the cut are dropped. If a style is still active at the cut, a
+// reset sequence is appended.
+func (w *Buffer) TruncateWidth(width int) {
+	b := w.Bytes()
+	cut := -1
+	var n widthCounter
+	var pos int
+
+	Parse(b, func(seq, text []byte) {
+		if cut >= 0 {
+			return
+		}
+		for i, c := range string(text) {
+			if n.add(c, runewidth.RuneWidth); n.n > width {
+				cut = pos + i
+				return
+			}
+		}
+		pos += len(seq) + len(text)
+	})
+	if cut < 0 {
+		// the content fits
+		return
+	}
+
+	active := !ActiveStyle(string(b[:cut])).IsZero()
+	w.Truncate(cut)
+	if active {
+		_, _ = w.WriteString("\x1b[0m")
+	}
+}
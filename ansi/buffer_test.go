@@ -3,6 +3,8 @@ package ansi
 import (
 	"bytes"
 	"testing"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func TestBuffer_PrintableRuneWidth(t *testing.T) {
@@ -29,3 +31,258 @@ func Benchmark_PrintableRuneWidth(b *testing.B) {
 		}
 	})
 }
+
+func TestPrintableGraphemeWidth(t *testing.T) {
+	t.Parallel()
+
+	if n := PrintableGraphemeWidth("\x1B[31m👨‍👩‍👧\x1B[0m"); n != 2 {
+		t.Fatalf("width should be 2, got %d", n)
+	}
+	if n := PrintableGraphemeWidth("नमस्ते"); n != 4 {
+		t.Fatalf("width should be 4, got %d", n)
+	}
+	if n := PrintableGraphemeWidth("🇩🇪🇫🇷"); n != 4 {
+		t.Fatalf("width should be 4, got %d", n)
+	}
+}
+
+func TestBuffer_TrimmedRuneWidth(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected int
+	}{
+		{"foo", 3},
+		{"foo   ", 3},
+		{" foo \t ", 4},
+		{"foo bar  ", 7},
+		{"   ", 0},
+		{"你好  ", 4},
+		{"\x1B[38;2;249;38;114mfoo \x1B[0m \x1B[31m", 3},
+	}
+
+	for i, tc := range tt {
+		var bb bytes.Buffer
+		bb.WriteString(tc.in)
+		b := Buffer{bb}
+
+		if n := b.TrimmedRuneWidth(); n != tc.expected {
+			t.Errorf("Test %d, width should be %d, got %d", i, tc.expected, n)
+		}
+	}
+}
+
+func TestPrintableRuneWidth_Hyperlink(t *testing.T) {
+	t.Parallel()
+
+	s := "\x1B]8;;https://example.com/a/very/long/path\x1B\\\x1B[4mlink\x1B[0m\x1B]8;;\x1B\\ text"
+	if n := PrintableRuneWidth(s); n != 9 {
+		t.Fatalf("width should be 9, got %d", n)
+	}
+
+	s = "\x1B]8;;https://example.com\alink\x1B]8;;\a"
+	if n := PrintableRuneWidth(s); n != 4 {
+		t.Fatalf("width should be 4, got %d", n)
+	}
+}
+
+func TestPrintableRuneWidth_VariationSelectors(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected int
+	}{
+		{"\u263A", 1},
+		// VS16 requests the wide emoji presentation:
+		{"\u263A\uFE0F", 2},
+		{"\u2764\uFE0F \u2714\uFE0F", 5},
+		{"\x1B[31m\u263A\uFE0F\x1B[0m", 2},
+		{"\u231A\uFE0F", 2},
+		// VS15 requests the narrow text presentation:
+		{"\u263A\uFE0E", 1},
+		{"\u231A\uFE0E", 1},
+		// Selectors without a base character take up no room:
+		{"\uFE0F", 0},
+		{"\uFE0Fa", 1},
+	}
+
+	for i, tc := range tt {
+		if n := PrintableRuneWidth(tc.in); n != tc.expected {
+			t.Errorf("Test %d, width of %q should be %d, got %d", i, tc.in, tc.expected, n)
+		}
+		if n := PrintableGraphemeWidth(tc.in); n != tc.expected {
+			t.Errorf("Test %d, grapheme width of %q should be %d, got %d", i, tc.in, tc.expected, n)
+		}
+	}
+
+	var b Buffer
+	b.WriteString("a\u263A\uFE0Fb")
+	cells := b.Cells()
+	if len(cells) != 4 || cells[1].Width != 2 || cells[2].Width != 0 {
+		t.Fatalf("unexpected cells %v", cells)
+	}
+}
+
+func TestBuffer_Clone(t *testing.T) {
+	t.Parallel()
+
+	var b Buffer
+	b.WriteString("\x1B[31mfoo")
+
+	c := b.Clone()
+	c.WriteString(" 你好\x1B[0m")
+	if s := b.String(); s != "\x1B[31mfoo" || b.PrintableRuneWidth() != 3 {
+		t.Fatalf("original should be unchanged, got %q", s)
+	}
+	if s := c.String(); s != "\x1B[31mfoo 你好\x1B[0m" || c.PrintableRuneWidth() != 8 {
+		t.Fatalf("unexpected clone %q", s)
+	}
+
+	// Truncating the original doesn't affect the clone either:
+	b.Truncate(0)
+	b.WriteString("bar")
+	if s := c.String(); s != "\x1B[31mfoo 你好\x1B[0m" {
+		t.Fatalf("clone should be unchanged, got %q", s)
+	}
+}
+
+func TestBuffer_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	var b Buffer
+	b.WriteString("\x1B[38;2;249;38;114m你好\x1B[0m")
+
+	var peer bytes.Buffer
+	n, err := b.WriteTo(&peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\x1B[38;2;249;38;114m你好\x1B[0m"; peer.String() != expected || n != int64(len(expected)) {
+		t.Fatalf("expected %q (%d bytes), got %q (%d bytes)", expected, len(expected), peer.String(), n)
+	}
+	if b.Len() != 0 || b.PrintableRuneWidth() != 0 {
+		t.Fatalf("buffer should be drained, got %q", b.String())
+	}
+}
+
+func TestBuffer_ReadFrom(t *testing.T) {
+	t.Parallel()
+
+	var b Buffer
+	b.WriteString("foo ")
+
+	peer := bytes.NewBufferString("\x1B[31m你好\x1B[0m")
+	n, err := b.ReadFrom(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 15 {
+		t.Fatalf("expected 15 bytes read, got %d", n)
+	}
+	if s := b.String(); s != "foo \x1B[31m你好\x1B[0m" {
+		t.Fatalf("unexpected content %q", s)
+	}
+	if w := b.PrintableRuneWidth(); w != 8 {
+		t.Fatalf("width should be 8, got %d", w)
+	}
+}
+
+func TestBuffer_TruncateWidth(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		width    int
+		expected string
+	}{
+		{"foobar", 10, "foobar"},
+		{"foobar", 3, "foo"},
+		{"foobar", 0, ""},
+		// Double-width runes straddling the cut are dropped:
+		{"你好", 3, "你"},
+		{"你好", 1, ""},
+		// Escape sequences are kept, open styles get reset:
+		{"\x1B[31mfoo\x1B[0mbar", 2, "\x1B[31mfo\x1B[0m"},
+		{"\x1B[31mfoo\x1B[0mbar", 4, "\x1B[31mfoo\x1B[0mb"},
+		{"\x1B[1m\x1B[38;2;249;38;114m你好\x1B[0m", 3, "\x1B[1m\x1B[38;2;249;38;114m你\x1B[0m"},
+		// Sequences in front of the cut are kept:
+		{"foo\x1B[31mbar", 3, "foo\x1B[31m\x1B[0m"},
+		{"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\x1B\\", 2, "\x1B]8;;https://example.com\x1B\\li"},
+	}
+
+	for i, tc := range tt {
+		var b Buffer
+		b.WriteString(tc.in)
+		b.TruncateWidth(tc.width)
+
+		if b.String() != tc.expected {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.expected, b.String())
+		}
+	}
+}
+
+func TestPrintableRuneWidthFunc(t *testing.T) {
+	t.Parallel()
+
+	s := "\x1B[31mαβγ\x1B[0m 你好"
+	if n := PrintableRuneWidthFunc(s, (&runewidth.Condition{}).RuneWidth); n != 8 {
+		t.Fatalf("width should be 8, got %d", n)
+	}
+	if n := PrintableRuneWidthFunc(s, (&runewidth.Condition{EastAsianWidth: true}).RuneWidth); n != 11 {
+		t.Fatalf("width should be 11, got %d", n)
+	}
+
+	var b Buffer
+	b.WriteString(s)
+	if n := b.PrintableRuneWidthFunc(func(rune) int { return 1 }); n != 6 {
+		t.Fatalf("width should be 6, got %d", n)
+	}
+}
+
+func TestBuffer_Cells(t *testing.T) {
+	t.Parallel()
+
+	red := Style{Foreground: "31"}
+	boldRed := Style{Bold: true, Foreground: "31"}
+	boldGreen := Style{Bold: true, Foreground: "32"}
+
+	tt := []struct {
+		in       string
+		expected []Cell
+	}{
+		{"", nil},
+		{"ab", []Cell{{'a', 1, Style{}}, {'b', 1, Style{}}}},
+		// Styles are carried forward until changed:
+		{
+			"\x1B[31ma\x1B[1mb\x1B[32mc",
+			[]Cell{{'a', 1, red}, {'b', 1, boldRed}, {'c', 1, boldGreen}},
+		},
+		// Resets mid-string:
+		{
+			"\x1B[1;31m你\x1B[0m好\x1B[31m!",
+			[]Cell{{'你', 2, boldRed}, {'好', 2, Style{}}, {'!', 1, red}},
+		},
+		// Other sequences are dropped:
+		{
+			"\x1B]8;;https://example.com\x1B\\\x1B[31mx\x1B[2K",
+			[]Cell{{'x', 1, red}},
+		},
+	}
+
+	for i, tc := range tt {
+		var b Buffer
+		b.WriteString(tc.in)
+		cells := b.Cells()
+
+		if len(cells) != len(tc.expected) {
+			t.Fatalf("Test %d, expected %d cells, got %v", i, len(tc.expected), cells)
+		}
+		for j, c := range cells {
+			if c != tc.expected[j] {
+				t.Errorf("Test %d, expected cell %d to be %v, got %v", i, j, tc.expected[j], c)
+			}
+		}
+	}
+}
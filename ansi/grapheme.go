@@ -0,0 +1,40 @@
+package ansi
+
+import (
+	"github.com/rivo/uniseg"
+)
+
+// Graphemes splits s into grapheme clusters, calling fn for each of them in
+// order along with their runes and printable width. A cluster is as wide as its
+// first rune which isn't zero-width, as measured by runeWidth, unless a
+// variation selector requests its narrow text or wide emoji presentation.
+// Flags, made of two regional indicators, are always wide.
+// Escape sequences aren't recognized, so their runes end up in clusters like
+// any other text.
+func Graphemes(s string, runeWidth func(rune) int, fn func(cluster string, runes []rune, width int)) {
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		runes := g.Runes()
+
+		var width int
+		for _, c := range runes {
+			if c == vs15 || c == vs16 {
+				if width > 0 {
+					width = presentationWidth(c)
+				}
+			} else if width == 0 {
+				width = runeWidth(c)
+			}
+		}
+		if len(runes) == 2 && isRegionalIndicator(runes[0]) && isRegionalIndicator(runes[1]) {
+			width = 2
+		}
+		fn(g.Str(), runes, width)
+	}
+}
+
+// isRegionalIndicator reports whether c is one of the regional indicator
+// symbols, pairs of which make up flags.
+func isRegionalIndicator(c rune) bool {
+	return c >= '\U0001F1E6' && c <= '\U0001F1FF'
+}
@@ -0,0 +1,75 @@
+package ansi
+
+import (
+	"unicode/utf8"
+)
+
+// Parse splits b into escape sequences and printable text, calling fn for each
+// of them in order. Either seq or text is set, the other one is nil. Adjacent
+// escape sequences are passed one at a time, while an incomplete sequence at
+// the end of b is passed as it is.
+func Parse(b []byte, fn func(seq, text []byte)) {
+	var state seqState
+	var inSeq, seq bool
+	var start int
+
+	emit := func(end int) {
+		if end == start {
+			return
+		}
+		if inSeq {
+			fn(b[start:end], nil)
+		} else {
+			fn(nil, b[start:end])
+		}
+		start = end
+	}
+
+	for i := 0; i < len(b); {
+		c, n := utf8.DecodeRune(b[i:])
+		state, seq = state.next(c)
+		if seq != inSeq {
+			// switching between text and an escape sequence
+			emit(i)
+			inSeq = seq
+		}
+
+		i += n
+		if seq && state == stateText {
+			// escape sequence terminated
+			emit(i)
+			inSeq = false
+		}
+	}
+	emit(len(b))
+}
+
+// Incomplete returns the offset of an escape sequence or a UTF-8 encoded rune
+// cut off at the end of b, or len(b) if there is none. Writers processing
+// their input in chunks hold it back until the next write.
+func Incomplete(b []byte) int {
+	var state seqState
+	start := len(b)
+	for i := 0; i < len(b); {
+		c, n := utf8.DecodeRune(b[i:])
+		prev := state
+		state, _ = state.next(c)
+		if prev == stateText && state != stateText {
+			start = i
+		}
+		i += n
+	}
+	if state != stateText {
+		return start
+	}
+
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,79 @@
+package ansi
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected []string
+	}{
+		// Text only:
+		{"foo bar", []string{"foo bar"}},
+		// SGR sequences:
+		{"\x1B[38;2;249;38;114mfoo\x1B[0m", []string{"<\x1B[38;2;249;38;114m>", "foo", "<\x1B[0m>"}},
+		{"\x1B[1m\x1B[31m你好", []string{"<\x1B[1m>", "<\x1B[31m>", "你好"}},
+		// Cursor movement and erasing:
+		{"foo\x1B[2Kbar\x1B[1;1H", []string{"foo", "<\x1B[2K>", "bar", "<\x1B[1;1H>"}},
+		// OSC 8 hyperlinks:
+		{"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\a", []string{"<\x1B]8;;https://example.com\x1B\\>", "link", "<\x1B]8;;\a>"}},
+		// Bare escapes:
+		{"\x1B7foo\x1B8", []string{"<\x1B7>", "foo", "<\x1B8>"}},
+		{"\x1B(Bfoo", []string{"<\x1B(B>", "foo"}},
+		// Incomplete sequences:
+		{"foo\x1B", []string{"foo", "<\x1B>"}},
+		{"foo\x1B[3", []string{"foo", "<\x1B[3>"}},
+	}
+
+	for i, tc := range tt {
+		var actual []string
+		Parse([]byte(tc.in), func(seq, text []byte) {
+			if seq != nil && text != nil {
+				t.Errorf("Test %d, got both a sequence and text", i)
+			}
+			if seq != nil {
+				actual = append(actual, "<"+string(seq)+">")
+			} else {
+				actual = append(actual, string(text))
+			}
+		})
+
+		if len(actual) != len(tc.expected) {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.expected, actual)
+			continue
+		}
+		for j := range actual {
+			if actual[j] != tc.expected[j] {
+				t.Errorf("Test %d, expected %q, got %q", i, tc.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected int
+	}{
+		{"foo", 3},
+		{"foo\x1B[31m", 8},
+		{"foo\x1B", 3},
+		{"foo\x1B[3", 3},
+		{"foo\x1B]8;;https://example.com", 3},
+		{"foo\x1B]8;;\x1B", 3},
+		{"foo你", 6},
+		{"foo\xE4\xBD", 3},
+	}
+
+	for i, tc := range tt {
+		if actual := Incomplete([]byte(tc.in)); actual != tc.expected {
+			t.Errorf("Test %d, expected %d, got %d", i, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package ansi
+
+import (
+	"strings"
+)
+
+// Strip removes all escape sequences from the given string, such as control
+// sequences (CSI) and operating system commands (OSC), keeping all printable
+// content and whitespace.
+func Strip(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+	Parse([]byte(s), func(_, text []byte) {
+		_, _ = b.Write(text)
+	})
+
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package ansi
+
+import (
+	"testing"
+)
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected string
+	}{
+		// No-op:
+		{"foo bar\n\tbaz", "foo bar\n\tbaz"},
+		// SGR sequences:
+		{"\x1B[38;2;249;38;114mfoo\x1B[0m bar", "foo bar"},
+		{"\x1B[1m\x1B[31m你好\x1B[0m", "你好"},
+		// Cursor movement:
+		{"foo\x1B[2Kbar\x1B[1;1H", "foobar"},
+		// OSC 8 hyperlinks terminated by ST:
+		{"\x1B]8;;https://example.com/path?q=1\x1B\\link\x1B]8;;\x1B\\", "link"},
+		// OSC terminated by BEL:
+		{"\x1B]0;window title\afoo", "foo"},
+		// Hyperlink with styled text:
+		{"\x1B]8;;https://example.com\x1B\\\x1B[4mlink\x1B[0m\x1B]8;;\x1B\\ text", "link text"},
+		// Other escapes:
+		{"\x1B7foo\x1B8", "foo"},
+		{"\x1B(Bfoo", "foo"},
+		// Incomplete sequence:
+		{"foo\x1B[3", "foo"},
+	}
+
+	for i, tc := range tt {
+		if s := Strip(tc.in); s != tc.expected {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.expected, s)
+		}
+	}
+}
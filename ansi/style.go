@@ -0,0 +1,359 @@
+package ansi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Style holds the SGR (select graphic rendition) attributes active at some
+// point of a styled text.
+type Style struct {
+	Bold       bool
+	Faint      bool
+	Italic     bool
+	Underline  bool
+	Blink      bool
+	Reverse    bool
+	Conceal    bool
+	CrossedOut bool
+
+	// Foreground and Background hold the parameters of the active colors,
+	// e.g. "31", "38;5;208" or "48;2;255;0;0". They are empty for the
+	// terminal's default colors.
+	Foreground string
+	Background string
+}
+
+// ActiveStyle returns the style active at the end of s, after applying all of
+// its SGR sequences.
+func ActiveStyle(s string) Style {
+	var style Style
+
+	Parse([]byte(s), func(seq, _ []byte) {
+		if len(seq) > 2 && seq[1] == '[' && seq[len(seq)-1] == 'm' {
+			style.Apply(string(seq[2 : len(seq)-1]))
+		}
+	})
+
+	return style
+}
+
+// IsZero reports whether no attributes are set.
+func (s Style) IsZero() bool {
+	return s == Style{}
+}
+
+// Apply updates the style with the parameters of an SGR sequence, i.e. the
+// part between "\x1b[" and "m", such as "1;38;2;255;0;0".
+func (s *Style) Apply(params string) {
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		if strings.Contains(p[i], ":") {
+			// colon separated sub-parameters, e.g. "38:2::255:0:0"
+			j := strings.Index(p[i], ":")
+			switch code, _ := strconv.Atoi(p[i][:j]); code {
+			case 4:
+				// an underline style, "4:0" turns it off
+				n, err := strconv.Atoi(p[i][j+1:])
+				s.Underline = err != nil || n != 0
+			case 38:
+				s.Foreground = p[i]
+			case 48:
+				s.Background = p[i]
+			}
+			continue
+		}
+
+		// an empty parameter is the same as zero
+		code, _ := strconv.Atoi(p[i])
+		switch {
+		case code == 0:
+			*s = Style{}
+		case code == 1:
+			s.Bold = true
+		case code == 2:
+			s.Faint = true
+		case code == 3:
+			s.Italic = true
+		case code == 4:
+			s.Underline = true
+		case code == 5 || code == 6:
+			s.Blink = true
+		case code == 7:
+			s.Reverse = true
+		case code == 8:
+			s.Conceal = true
+		case code == 9:
+			s.CrossedOut = true
+		case code == 21:
+			// treated as bold off by many terminals
+			s.Bold = false
+		case code == 22:
+			s.Bold = false
+			s.Faint = false
+		case code == 23:
+			s.Italic = false
+		case code == 24:
+			s.Underline = false
+		case code == 25:
+			s.Blink = false
+		case code == 27:
+			s.Reverse = false
+		case code == 28:
+			s.Conceal = false
+		case code == 29:
+			s.CrossedOut = false
+		case code >= 30 && code <= 37, code >= 90 && code <= 97:
+			s.Foreground = strconv.Itoa(code)
+		case code == 39:
+			s.Foreground = ""
+		case code >= 40 && code <= 47, code >= 100 && code <= 107:
+			s.Background = strconv.Itoa(code)
+		case code == 49:
+			s.Background = ""
+		case code == 38 || code == 48:
+			color, n, ok := extendedColor(p, i)
+			i = n
+			if !ok {
+				continue
+			}
+			if code == 38 {
+				s.Foreground = color
+			} else {
+				s.Background = color
+			}
+		}
+	}
+}
+
+// extendedColor returns the 256-color or truecolor parameters starting at
+// p[i], and the index of their last parameter. It reports false for malformed
+// colors.
+func extendedColor(p []string, i int) (string, int, bool) {
+	n := i
+	if i+1 < len(p) {
+		switch mode, _ := strconv.Atoi(p[i+1]); mode {
+		case 5:
+			n = i + 2
+		case 2:
+			n = i + 4
+		}
+	}
+	if n == i || n >= len(p) {
+		// malformed, skip the remaining parameters
+		return "", len(p), false
+	}
+
+	codes := make([]string, 0, n-i+1)
+	for _, v := range p[i : n+1] {
+		code, _ := strconv.Atoi(v)
+		codes = append(codes, strconv.Itoa(code))
+	}
+	return strings.Join(codes, ";"), n, true
+}
+
+// Codes returns the SGR parameters of all active attributes.
+func (s Style) Codes() []string {
+	var codes []string
+	for _, a := range []struct {
+		set  bool
+		code string
+	}{
+		{s.Bold, "1"},
+		{s.Faint, "2"},
+		{s.Italic, "3"},
+		{s.Underline, "4"},
+		{s.Blink, "5"},
+		{s.Reverse, "7"},
+		{s.Conceal, "8"},
+		{s.CrossedOut, "9"},
+		{s.Foreground != "", s.Foreground},
+		{s.Background != "", s.Background},
+	} {
+		if a.set {
+			codes = append(codes, a.code)
+		}
+	}
+
+	return codes
+}
+
+// Sequence returns a single SGR sequence enabling all active attributes, or
+// an empty string if none are set.
+func (s Style) Sequence() string {
+	codes := s.Codes()
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// Transition returns a single SGR sequence switching from the style s to the
+// style to, or an empty string if they are the same. It turns off attributes
+// individually, unless resetting all of them and enabling the remaining ones
+// is shorter.
+func (s Style) Transition(to Style) string {
+	if s == to {
+		return ""
+	}
+
+	codes := strings.Join(s.changes(to), ";")
+	if reset := strings.Join(append([]string{"0"}, to.Codes()...), ";"); len(reset) < len(codes) {
+		codes = reset
+	}
+	return "\x1b[" + codes + "m"
+}
+
+// changes returns the SGR parameters turning the attributes of s, which aren't
+// set in to, off and the ones missing in s on.
+func (s Style) changes(to Style) []string {
+	var codes []string
+	from := s
+	if from.Bold && !to.Bold || from.Faint && !to.Faint {
+		// turns off both
+		codes = append(codes, "22")
+		from.Bold, from.Faint = false, false
+	}
+	for _, a := range []struct {
+		from *bool
+		to   bool
+		code string
+	}{
+		{&from.Italic, to.Italic, "23"},
+		{&from.Underline, to.Underline, "24"},
+		{&from.Blink, to.Blink, "25"},
+		{&from.Reverse, to.Reverse, "27"},
+		{&from.Conceal, to.Conceal, "28"},
+		{&from.CrossedOut, to.CrossedOut, "29"},
+	} {
+		if *a.from && !a.to {
+			codes = append(codes, a.code)
+			*a.from = false
+		}
+	}
+
+	on := Style{
+		Bold:       to.Bold && !from.Bold,
+		Faint:      to.Faint && !from.Faint,
+		Italic:     to.Italic && !from.Italic,
+		Underline:  to.Underline && !from.Underline,
+		Blink:      to.Blink && !from.Blink,
+		Reverse:    to.Reverse && !from.Reverse,
+		Conceal:    to.Conceal && !from.Conceal,
+		CrossedOut: to.CrossedOut && !from.CrossedOut,
+	}
+	codes = append(codes, on.Codes()...)
+
+	if to.Foreground != from.Foreground {
+		if to.Foreground == "" {
+			codes = append(codes, "39")
+		} else {
+			codes = append(codes, to.Foreground)
+		}
+	}
+	if to.Background != from.Background {
+		if to.Background == "" {
+			codes = append(codes, "49")
+		} else {
+			codes = append(codes, to.Background)
+		}
+	}
+
+	return codes
+}
+
+// NormalizeStyles replaces every run of adjacent SGR sequences in s by the
+// shortest single sequence with the same effect, dropping the runs without
+// any. Runs holding codes Style doesn't keep track of, such as overlines, are
+// kept as they are, as are all following runs until the next reset.
+func NormalizeStyles(s string) string {
+	var b strings.Builder
+	var style, runStyle Style
+	var run []byte
+	known, runKnown := true, true
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+
+		switch {
+		case !runKnown:
+			_, _ = b.Write(run)
+		case known:
+			_, _ = b.WriteString(style.Transition(runStyle))
+		default:
+			// the attributes unknown to Style are only turned off by a reset
+			_, _ = b.WriteString("\x1b[" + strings.Join(append([]string{"0"}, runStyle.Codes()...), ";") + "m")
+		}
+
+		style, known = runStyle, runKnown
+		run = run[:0]
+	}
+
+	Parse([]byte(s), func(seq, text []byte) {
+		if len(seq) > 2 && seq[1] == '[' && seq[len(seq)-1] == 'm' {
+			params := string(seq[2 : len(seq)-1])
+			run = append(run, seq...)
+			runStyle.Apply(params)
+			runKnown = knownParams(params, runKnown)
+			return
+		}
+
+		flush()
+		_, _ = b.Write(seq)
+		_, _ = b.Write(text)
+	})
+	flush()
+
+	return b.String()
+}
+
+// knownParams reports whether Style keeps track of all attributes set after
+// applying the parameters of an SGR sequence, given whether it did before.
+func knownParams(params string, known bool) bool {
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		if j := strings.Index(p[i], ":"); j >= 0 {
+			if code := p[i][:j]; code != "38" && code != "48" {
+				known = false
+			}
+			continue
+		}
+
+		// an empty parameter is the same as zero
+		code, err := strconv.Atoi(p[i])
+		switch {
+		case p[i] == "" || err == nil && code == 0:
+			known = true
+		case err != nil:
+			known = false
+		case code == 38 || code == 48:
+			_, n, ok := extendedColor(p, i)
+			i = n
+			if !ok {
+				known = false
+			}
+		case !KnownSGR(code):
+			known = false
+		}
+	}
+
+	return known
+}
+
+// KnownSGR reports whether Style keeps track of the effect of an SGR code,
+// which needs no further parameters, and encodes it the same way. Codes with
+// an effect it only approximates, such as rapid blinking or 21, which is
+// either bold off or a double underline, aren't known.
+func KnownSGR(code int) bool {
+	return code >= 0 && code <= 5 ||
+		code >= 7 && code <= 9 ||
+		code >= 22 && code <= 25 ||
+		code >= 27 && code <= 37 ||
+		code == 39 ||
+		code >= 40 && code <= 47 ||
+		code == 49 ||
+		code >= 90 && code <= 97 ||
+		code >= 100 && code <= 107
+}
@@ -0,0 +1,155 @@
+package ansi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestActiveStyle(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected Style
+		sequence string
+	}{
+		// No styling:
+		{"foo", Style{}, ""},
+		// Basic attributes:
+		{"\x1B[1mfoo\x1B[4mbar", Style{Bold: true, Underline: true}, "\x1b[1;4m"},
+		{"\x1B[1;3;7;9mfoo", Style{Bold: true, Italic: true, Reverse: true, CrossedOut: true}, "\x1b[1;3;7;9m"},
+		// Reset:
+		{"\x1B[1;31mfoo\x1B[0mbar", Style{}, ""},
+		{"\x1B[1;31mfoo\x1B[mbar", Style{}, ""},
+		{"\x1B[1;31;0;4mfoo", Style{Underline: true}, "\x1b[4m"},
+		// Partial resets:
+		{"\x1B[1;2;3mfoo\x1B[22m", Style{Italic: true}, "\x1b[3m"},
+		{"\x1B[1;3mfoo\x1B[21m", Style{Italic: true}, "\x1b[3m"},
+		{"\x1B[31;42mfoo\x1B[39m", Style{Background: "42"}, "\x1b[42m"},
+		{"\x1B[31;42mfoo\x1B[49m", Style{Foreground: "31"}, "\x1b[31m"},
+		// Colors replace each other:
+		{"\x1B[31mfoo\x1B[92m", Style{Foreground: "92"}, "\x1b[92m"},
+		// 256 colors:
+		{"\x1B[38;5;208;48;5;17mfoo", Style{Foreground: "38;5;208", Background: "48;5;17"}, "\x1b[38;5;208;48;5;17m"},
+		// Truecolor:
+		{"\x1B[38;2;249;38;114mfoo", Style{Foreground: "38;2;249;38;114"}, "\x1b[38;2;249;38;114m"},
+		{"\x1B[1;48;2;0;0;255;4m", Style{Bold: true, Underline: true, Background: "48;2;0;0;255"}, "\x1b[1;4;48;2;0;0;255m"},
+		// Leading zeros:
+		{"\x1B[0031;0001m", Style{Bold: true, Foreground: "31"}, "\x1b[1;31m"},
+		// Colon separated truecolor:
+		{"\x1B[38:2::255:0:0m", Style{Foreground: "38:2::255:0:0"}, "\x1b[38:2::255:0:0m"},
+		// Underline styles:
+		{"\x1B[4:3mfoo", Style{Underline: true}, "\x1b[4m"},
+		{"\x1B[1;4mfoo\x1B[4:0m", Style{Bold: true}, "\x1b[1m"},
+		// Malformed colors are ignored:
+		{"\x1B[31m\x1B[38;5m", Style{Foreground: "31"}, "\x1b[31m"},
+		// Other sequences are ignored:
+		{"\x1B[1m\x1B[2K\x1B]8;;https://example.com/1m\x1B\\", Style{Bold: true}, "\x1b[1m"},
+	}
+
+	for i, tc := range tt {
+		s := ActiveStyle(tc.in)
+		if s != tc.expected {
+			t.Errorf("Test %d, expected %+v, got %+v", i, tc.expected, s)
+		}
+		if seq := s.Sequence(); seq != tc.sequence {
+			t.Errorf("Test %d, expected sequence %q, got %q", i, tc.sequence, seq)
+		}
+	}
+}
+
+func TestWriter_Style(t *testing.T) {
+	t.Parallel()
+
+	w := &Writer{Forward: &bytes.Buffer{}}
+
+	if _, err := w.Write([]byte("\x1B[1mfoo\x1B[38;2;249;38;114mbar")); err != nil {
+		t.Fatal(err)
+	}
+	expected := Style{Bold: true, Foreground: "38;2;249;38;114"}
+	if s := w.Style(); s != expected {
+		t.Fatalf("expected %+v, got %+v", expected, s)
+	}
+
+	if _, err := w.Write([]byte("\x1B[22;48;5;17mbaz")); err != nil {
+		t.Fatal(err)
+	}
+	expected = Style{Foreground: "38;2;249;38;114", Background: "48;5;17"}
+	if s := w.Style(); s != expected {
+		t.Fatalf("expected %+v, got %+v", expected, s)
+	}
+
+	if _, err := w.Write([]byte("\x1B[0m")); err != nil {
+		t.Fatal(err)
+	}
+	if s := w.Style(); !s.IsZero() {
+		t.Fatalf("expected no style, got %+v", s)
+	}
+}
+
+func TestStyle_Transition(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		from     Style
+		to       Style
+		expected string
+	}{
+		{Style{}, Style{}, ""},
+		{Style{Bold: true}, Style{Bold: true}, ""},
+		// Enabling attributes:
+		{Style{}, Style{Bold: true, Foreground: "31"}, "\x1b[1;31m"},
+		{Style{Bold: true}, Style{Bold: true, Italic: true}, "\x1b[3m"},
+		// Turning attributes off:
+		{Style{Bold: true, Italic: true}, Style{Bold: true}, "\x1b[23m"},
+		{Style{Bold: true, Faint: true, Italic: true}, Style{Faint: true, Italic: true}, "\x1b[22;2m"},
+		{Style{Foreground: "31", Background: "42"}, Style{Background: "42"}, "\x1b[39m"},
+		// Resetting is shorter:
+		{Style{Bold: true, Foreground: "31"}, Style{}, "\x1b[0m"},
+		{Style{Bold: true, Italic: true, Underline: true}, Style{Foreground: "32"}, "\x1b[0;32m"},
+		// Truecolor:
+		{Style{Foreground: "31"}, Style{Foreground: "38;2;255;0;0"}, "\x1b[38;2;255;0;0m"},
+	}
+
+	for i, tc := range tt {
+		if seq := tc.from.Transition(tc.to); seq != tc.expected {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.expected, seq)
+		}
+	}
+}
+
+func TestNormalizeStyles(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in       string
+		expected string
+	}{
+		{"foo", "foo"},
+		// Duplicates:
+		{"\x1B[1m\x1B[1m\x1B[31mfoo", "\x1b[1;31mfoo"},
+		{"\x1B[31mfoo\x1B[31mbar", "\x1b[31mfoobar"},
+		{"\x1B[1mfoo\x1B[1;1mbar\x1B[0m", "\x1b[1mfoobar\x1b[0m"},
+		// Resets:
+		{"\x1B[0m\x1B[0mfoo\x1B[0m", "foo"},
+		{"\x1B[31m\x1B[0m\x1B[32mfoo\x1B[m", "\x1b[32mfoo\x1b[0m"},
+		{"\x1B[1;3mfoo\x1B[0m\x1B[1mbar", "\x1b[1;3mfoo\x1b[23mbar"},
+		{"\x1B[1;4mfoo\x1B[0m\x1B[32mbar", "\x1b[1;4mfoo\x1b[0;32mbar"},
+		// Truecolor and 256 colors:
+		{"\x1B[38;2;255;0;0m\x1B[1mfoo", "\x1b[1;38;2;255;0;0mfoo"},
+		{"\x1B[38;5;208m\x1B[48;2;0;0;255mfoo\x1B[0m", "\x1b[38;5;208;48;2;0;0;255mfoo\x1b[0m"},
+		{"\x1B[38:2::255:0:0m\x1B[38:2::255:0:0mfoo", "\x1b[38:2::255:0:0mfoo"},
+		// Other sequences end runs:
+		{"\x1B[1m\x1B[2K\x1B[1mfoo", "\x1b[1m\x1B[2Kfoo"},
+		// Unknown attributes are kept until reset:
+		{"\x1B[53m\x1B[53mfoo\x1B[1mbar\x1B[0mbaz", "\x1B[53m\x1B[53mfoo\x1B[1mbar\x1b[0mbaz"},
+		{"\x1B[1;53mfoo\x1B[0;31mbar\x1B[31mbaz", "\x1B[1;53mfoo\x1b[0;31mbarbaz"},
+		{"\x1B[6m\x1B[6mfoo\x1B[0mbar", "\x1B[6m\x1B[6mfoo\x1b[0mbar"},
+	}
+
+	for i, tc := range tt {
+		if s := NormalizeStyles(tc.in); s != tc.expected {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.expected, s)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ansi
+
+const (
+	// vs15 is the variation selector requesting the text presentation of the
+	// preceding character, which is narrow.
+	vs15 = '\uFE0E'
+	// vs16 is the variation selector requesting the emoji presentation of
+	// the preceding character, which is wide.
+	vs16 = '\uFE0F'
+)
+
+// widthCounter sums up the printable widths of consecutive runes. A variation
+// selector doesn't take up room of its own, but changes the width of the rune
+// in front of it to the one of the requested presentation.
+type widthCounter struct {
+	n    int // the total width
+	last int // the width of the last rune
+}
+
+// add counts c, measured by runeWidth, returning how much the total width
+// changed.
+func (w *widthCounter) add(c rune, runeWidth func(rune) int) int {
+	var d int
+	switch {
+	case c == vs15 || c == vs16:
+		if w.last > 0 {
+			d = presentationWidth(c) - w.last
+			w.last += d
+		}
+	default:
+		d = runeWidth(c)
+		w.last = d
+	}
+
+	w.n += d
+	return d
+}
+
+// presentationWidth returns the width of a character shown in the
+// presentation requested by the variation selector c.
+func presentationWidth(c rune) int {
+	if c == vs16 {
+		return 2
+	}
+	return 1
+}
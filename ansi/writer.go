@@ -4,54 +4,89 @@ import (
 	"bytes"
 	"io"
 	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
 )
 
 type Writer struct {
 	Forward io.Writer
 
-	ansi       bool
+	state      seqState
 	ansiseq    bytes.Buffer
 	lastseq    bytes.Buffer
 	seqchanged bool
+	style      Style
+	width      widthCounter
 	runeBuf    []byte
 }
 
+// NewWriter returns a new Writer passing everything written to it straight
+// through to forward. Nothing is buffered, apart from incomplete escape
+// sequences, while the printable width written so far is tracked.
+func NewWriter(forward io.Writer) *Writer {
+	return &Writer{Forward: forward}
+}
+
 // Write is used to write content to the ANSI buffer.
 func (w *Writer) Write(b []byte) (int, error) {
 	for _, c := range string(b) {
-		if c == Marker {
-			// ANSI escape sequence
-			w.ansi = true
-			w.seqchanged = true
-			_, _ = w.ansiseq.WriteRune(c)
-		} else if w.ansi {
+		var seq bool
+		prev := w.state
+		w.state, seq = w.state.next(c)
+		if seq {
+			if prev == stateText {
+				// ANSI escape sequence
+				w.seqchanged = true
+			}
 			_, _ = w.ansiseq.WriteRune(c)
-			if IsTerminator(c) {
+			if w.state == stateText {
 				// ANSI sequence terminated
-				w.ansi = false
-
-				if bytes.HasSuffix(w.ansiseq.Bytes(), []byte("[0m")) {
-					// reset sequence
-					w.lastseq.Reset()
-					w.seqchanged = false
-				} else if c == 'm' {
-					// color code
-					_, _ = w.lastseq.Write(w.ansiseq.Bytes())
+				w.addSequence(c)
+				if _, err := w.ansiseq.WriteTo(w.Forward); err != nil {
+					return 0, err
 				}
-
-				_, _ = w.ansiseq.WriteTo(w.Forward)
 			}
 		} else {
 			_, err := w.writeRune(c)
 			if err != nil {
 				return 0, err
 			}
+			w.width.add(c, runewidth.RuneWidth)
 		}
 	}
 
 	return len(b), nil
 }
 
+// addSequence keeps track of the styling of the terminated sequence, ending
+// with c.
+func (w *Writer) addSequence(c rune) {
+	seq := w.ansiseq.Bytes()
+	isSGR := c == 'm' && bytes.HasPrefix(seq, []byte("\x1b["))
+	if isSGR && bytes.HasSuffix(seq, []byte("[0m")) {
+		// reset sequence
+		w.lastseq.Reset()
+		w.seqchanged = false
+	} else if isSGR {
+		// color code
+		_, _ = w.lastseq.Write(seq)
+	}
+	if isSGR {
+		w.style.Apply(string(seq[2 : len(seq)-1]))
+	}
+}
+
+// Flush forwards the bytes of an escape sequence which is still incomplete,
+// e.g. when no more content follows.
+func (w *Writer) Flush() error {
+	w.state = stateText
+	if w.ansiseq.Len() == 0 {
+		return nil
+	}
+	_, err := w.ansiseq.WriteTo(w.Forward)
+	return err
+}
+
 func (w *Writer) writeRune(r rune) (int, error) {
 	if w.runeBuf == nil {
 		w.runeBuf = make([]byte, utf8.UTFMax)
@@ -64,6 +99,17 @@ func (w *Writer) LastSequence() string {
 	return w.lastseq.String()
 }
 
+// PrintableWidth returns the cell width of all printable runes written so far,
+// ignoring escape sequences.
+func (w *Writer) PrintableWidth() int {
+	return w.width.n
+}
+
+// Style returns the SGR attributes active after everything written so far.
+func (w *Writer) Style() Style {
+	return w.style
+}
+
 func (w *Writer) ResetAnsi() {
 	if !w.seqchanged {
 		return
@@ -37,6 +37,51 @@ func TestWriter_Write(t *testing.T) {
 	}
 }
 
+func TestWriter_Hyperlink(t *testing.T) {
+	t.Parallel()
+
+	buf := []byte("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\")
+	forward := &bytes.Buffer{}
+	w := &Writer{Forward: forward}
+
+	if _, err := w.Write(buf); err != nil {
+		t.Fatalf("err should be nil, but got %v", err)
+	}
+	if n := w.PrintableWidth(); n != 4 {
+		t.Fatalf("width should be 4, got %d", n)
+	}
+	if b := forward.Bytes(); !bytes.Equal(b, buf) {
+		t.Fatalf("forward should be wrote by %q, but got %q", buf, b)
+	}
+}
+
+func TestWriter_Flush(t *testing.T) {
+	t.Parallel()
+
+	forward := &bytes.Buffer{}
+	w := &Writer{Forward: forward}
+
+	// split sequences are forwarded once complete
+	for _, s := range []string{"foo\x1b[3", "1mbar\x1b]8;;http"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("err should be nil, but got %v", err)
+		}
+	}
+	if s := forward.String(); s != "foo\x1b[31mbar" {
+		t.Fatalf("forward should hold %q, got %q", "foo\x1b[31mbar", s)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("err should be nil, but got %v", err)
+	}
+	if s := forward.String(); s != "foo\x1b[31mbar\x1b]8;;http" {
+		t.Fatalf("forward should hold %q, got %q", "foo\x1b[31mbar\x1b]8;;http", s)
+	}
+	if n := w.PrintableWidth(); n != 6 {
+		t.Fatalf("width should be 6, got %d", n)
+	}
+}
+
 var fakeErr = errors.New("fake error")
 
 type fakeWriter struct{}
@@ -114,3 +159,39 @@ func TestWriter_RestoreAnsi(t *testing.T) {
 		t.Fatalf("b.String() should be \"\\x1B[38;2;249;38;114m\", got %s", s)
 	}
 }
+
+func TestWriter_PrintableWidth(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    []string
+		Expected int
+	}{
+		{[]string{""}, 0},
+		{[]string{"foo"}, 3},
+		{[]string{"\x1B[38;2;249;38;114m你好reflow\x1B[0m"}, 10},
+		// Sequences split between writes:
+		{[]string{"foo\x1B[3", "1mbar\x1B", "[0m"}, 6},
+		{[]string{"foo\n", "bar"}, 6},
+	}
+
+	for i, tc := range tt {
+		var forward bytes.Buffer
+		w := NewWriter(&forward)
+
+		var input string
+		for _, s := range tc.Input {
+			input += s
+			if _, err := w.Write([]byte(s)); err != nil {
+				t.Fatalf("Test %d: err should be nil, but got %v", i, err)
+			}
+		}
+
+		if forward.String() != input {
+			t.Errorf("Test %d: forward should be %q, but got %q", i, input, forward.String())
+		}
+		if n := w.PrintableWidth(); n != tc.Expected {
+			t.Errorf("Test %d: PrintableWidth should be %d, but got %d", i, tc.Expected, n)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package columns
+
+import (
+	"strings"
+
+	"github.com/muesli/reflow/align"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+// Column describes the printable width of a column and how its cells are
+// aligned within it.
+type Column struct {
+	Width int
+	Align align.Position
+}
+
+// Layout lays out single-line cells in columns of a fixed width.
+type Layout struct {
+	Columns   []Column
+	Separator string // placed between the cells of a row
+	Ellipsis  string // ends the cells which had to be truncated
+}
+
+// New returns a new layout of the given columns, using "…" to mark truncated
+// cells.
+func New(separator string, columns ...Column) *Layout {
+	return &Layout{
+		Columns:   columns,
+		Separator: separator,
+		Ellipsis:  "…",
+	}
+}
+
+// Row returns a single line of the given cells, each padded or truncated to
+// the width of its column. Missing cells are left blank, surplus ones are
+// ignored.
+func (l *Layout) Row(cells ...string) string {
+	var b strings.Builder
+
+	for i, col := range l.Columns {
+		if i > 0 {
+			_, _ = b.WriteString(l.Separator)
+		}
+
+		var cell string
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		_, _ = b.WriteString(l.cell(cell, col))
+	}
+
+	return b.String()
+}
+
+// cell fits the content of a cell to its column.
+func (l *Layout) cell(s string, col Column) string {
+	if col.Width <= 0 {
+		return ""
+	}
+	switch w := ansi.PrintableRuneWidth(s); {
+	case w == 0:
+		// blank cells aren't padded by the aligner
+		return s + strings.Repeat(" ", col.Width)
+	case w > col.Width:
+		s = truncate.StringWithTail(s, uint(col.Width), l.Ellipsis)
+	}
+
+	return align.String(s, col.Width, col.Align)
+}
@@ -0,0 +1,64 @@
+package columns
+
+import (
+	"testing"
+
+	"github.com/muesli/reflow/align"
+	"github.com/muesli/reflow/ansi"
+)
+
+func TestRow(t *testing.T) {
+	t.Parallel()
+
+	l := New(" | ",
+		Column{Width: 6},
+		Column{Width: 5, Align: align.Right},
+		Column{Width: 7, Align: align.Center},
+	)
+
+	tt := []struct {
+		cells    []string
+		expected string
+	}{
+		// Narrower cells are padded:
+		{
+			[]string{"foo", "12", "bar"},
+			"foo    |    12 |   bar  ",
+		},
+		// Wider cells are truncated:
+		{
+			[]string{"foobarbaz", "123456", "foo bar baz"},
+			"fooba… | 1234… | foo ba…",
+		},
+		// Exactly fitting cells are kept:
+		{
+			[]string{"foobar", "12345", "foo bar"},
+			"foobar | 12345 | foo bar",
+		},
+		// Styled cells:
+		{
+			[]string{"\x1B[31mfoo\x1B[0m", "\x1B[1m123456\x1B[0m", "bar"},
+			"\x1B[31mfoo\x1B[0m    | \x1B[1m1234\x1B[0m… |   bar  ",
+		},
+		// Double-width cells:
+		{
+			[]string{"你好", "你好世界", "世界"},
+			"你好   | 你好… |  世界  ",
+		},
+		// Missing cells are blank:
+		{
+			[]string{"foo"},
+			"foo    |       |        ",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := l.Row(tc.cells...)
+		if actual != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.expected, actual)
+		}
+		if w := ansi.PrintableRuneWidth(actual); w != 24 {
+			t.Errorf("Test %d, expected a width of 24, got %d", i, w)
+		}
+	}
+}
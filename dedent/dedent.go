@@ -2,6 +2,7 @@ package dedent
 
 import (
 	"bytes"
+	"strings"
 )
 
 // String automatically detects the maximum indentation shared by all lines and
@@ -15,6 +16,35 @@ func String(s string) string {
 	return dedent(s, indent)
 }
 
+// CommonIndent returns the longest leading whitespace shared by all lines which
+// aren't blank. Tabs and spaces are compared literally, so lines indented with
+// a tab and with spaces share no indentation.
+func CommonIndent(s string) string {
+	var common string
+	first := true
+	for _, l := range strings.Split(s, "\n") {
+		content := strings.TrimLeft(l, " \t")
+		if content == "" {
+			// blank line
+			continue
+		}
+
+		indent := l[:len(l)-len(content)]
+		if first {
+			common = indent
+			first = false
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(indent) && common[n] == indent[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	return common
+}
+
 func minIndent(s string) int {
 	var (
 		curIndent    int
@@ -71,3 +101,92 @@ func dedent(s string, indent int) string {
 
 	return buf.String()
 }
+
+// DefaultTabWidth is the tab stop used by StringTabs if no valid tab width
+// is given.
+const DefaultTabWidth = 8
+
+// StringTabs works like String, but measures the indentation in visual
+// columns: a tab advances to the next multiple of tabWidth. If expandTabs is
+// set, the remaining indentation is emitted as spaces. Otherwise tabs are kept,
+// unless the omitted columns aren't a multiple of tabWidth: the kept tabs would
+// then end at different tab stops, so the indentation is expanded to spaces.
+func StringTabs(s string, tabWidth int, expandTabs bool) string {
+	if tabWidth <= 0 {
+		tabWidth = DefaultTabWidth
+	}
+
+	lines := strings.Split(s, "\n")
+	indent := -1
+	for _, l := range lines {
+		col, content := indentColumns(l, tabWidth)
+		if content && (indent < 0 || col < indent) {
+			indent = col
+		}
+	}
+	if indent <= 0 && !expandTabs {
+		return s
+	}
+
+	var buf bytes.Buffer
+	for i, l := range lines {
+		if i > 0 {
+			_ = buf.WriteByte('\n')
+		}
+		dedentColumns(&buf, l, indent, tabWidth, expandTabs)
+	}
+
+	return buf.String()
+}
+
+// indentColumns returns the visual width of the leading whitespace of a line,
+// and whether the line has any other content.
+func indentColumns(line string, tabWidth int) (int, bool) {
+	var col int
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col = (col/tabWidth + 1) * tabWidth
+		default:
+			return col, true
+		}
+	}
+	return col, false
+}
+
+// dedentColumns writes the line to buf, omitting the given amount of columns
+// of leading whitespace.
+func dedentColumns(buf *bytes.Buffer, line string, indent, tabWidth int, expandTabs bool) {
+	if indent%tabWidth != 0 {
+		expandTabs = true
+	}
+
+	var col int
+	for i := 0; i < len(line); i++ {
+		var next int
+		switch line[i] {
+		case ' ':
+			next = col + 1
+		case '\t':
+			next = (col/tabWidth + 1) * tabWidth
+		default:
+			_, _ = buf.WriteString(line[i:])
+			return
+		}
+
+		switch {
+		case next <= indent:
+			// omitted
+		case col < indent:
+			// the whitespace got cut
+			_, _ = buf.WriteString(strings.Repeat(" ", next-indent))
+		case expandTabs:
+			_, _ = buf.WriteString(strings.Repeat(" ", next-col))
+		default:
+			_ = buf.WriteByte(line[i])
+		}
+		col = next
+	}
+}
@@ -51,6 +51,48 @@ func TestDedent(t *testing.T) {
 	}
 }
 
+func TestCommonIndent(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+	}{
+		{
+			Input:    "    foo\n      bar\n    baz\n",
+			Expected: "    ",
+		},
+		// blank lines are ignored:
+		{
+			Input:    "\t\tfoo\n\n \n\t\tbar",
+			Expected: "\t\t",
+		},
+		// mixed indentation only shares the identical prefix:
+		{
+			Input:    "\t  foo\n\t\tbar",
+			Expected: "\t",
+		},
+		// tabs and spaces share no indentation:
+		{
+			Input:    "\tfoo\n    bar",
+			Expected: "",
+		},
+		{
+			Input:    "foo\n  bar",
+			Expected: "",
+		},
+		{
+			Input:    "\n\n",
+			Expected: "",
+		},
+	}
+
+	for i, tc := range tt {
+		s := CommonIndent(tc.Input)
+		if s != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, s)
+		}
+	}
+}
+
 // go test -bench=BenchmarkDedent -benchmem -count=4
 func BenchmarkDedent(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
@@ -62,3 +104,74 @@ func BenchmarkDedent(b *testing.B) {
 		}
 	})
 }
+
+func TestDedentTabs(t *testing.T) {
+	tt := []struct {
+		Input      string
+		Expected   string
+		TabWidth   int
+		ExpandTabs bool
+	}{
+		// A tab is as wide as the spaces up to the next tab stop:
+		{
+			Input:    "\tline 1\n        line 2\n",
+			Expected: "line 1\nline 2\n",
+		},
+		// Tabs following spaces advance to the next tab stop:
+		{
+			Input:      "  \tline 1\n  line 2\n",
+			Expected:   "  line 1\nline 2\n",
+			TabWidth:   4,
+			ExpandTabs: true,
+		},
+		// Cut tabs are replaced by spaces:
+		{
+			Input:    "\t  line 1\n    line 2\n",
+			Expected: "      line 1\nline 2\n",
+		},
+		// Different depths, keeping tabs:
+		{
+			Input:    "\t\tline 1\n\tline 2\n\t\t\tline 3",
+			Expected: "\tline 1\nline 2\n\t\tline 3",
+		},
+		// Tabs following a cut are expanded to keep their columns:
+		{
+			Input:    "\t\tline 1\n\t    line 2\n\t\t\tline 3",
+			Expected: "    line 1\nline 2\n            line 3",
+		},
+		{
+			Input:    "\t\tfoo\n    bar",
+			Expected: "            foo\nbar",
+		},
+		// Different depths, expanding tabs:
+		{
+			Input:      "\t\tline 1\n\t    line 2\n\t\t\tline 3",
+			Expected:   "    line 1\nline 2\n            line 3",
+			ExpandTabs: true,
+		},
+		// Custom tab width:
+		{
+			Input:      "\t\tline 1\n  \tline 2",
+			Expected:   "    line 1\nline 2",
+			TabWidth:   4,
+			ExpandTabs: true,
+		},
+		// Blank lines are ignored:
+		{
+			Input:    "\t\tline 1\n\n\t\n\t\tline 2",
+			Expected: "line 1\n\n\nline 2",
+		},
+		// Unindented lines:
+		{
+			Input:    "line 1\n\tline 2",
+			Expected: "line 1\n\tline 2",
+		},
+	}
+
+	for i, tc := range tt {
+		s := StringTabs(tc.Input, tc.TabWidth, tc.ExpandTabs)
+		if s != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, s)
+		}
+	}
+}
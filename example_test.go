@@ -0,0 +1,33 @@
+package reflow_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muesli/reflow"
+	"github.com/muesli/reflow/indent"
+	"github.com/muesli/reflow/padding"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// Word-wrap a text, indent it and pad all lines to the same width.
+func ExamplePipe() {
+	p := reflow.Pipe(
+		wordwrap.NewWriter(10),
+		indent.NewWriter(2, nil),
+		padding.NewWriter(14, nil),
+	)
+
+	_, _ = p.Write([]byte("The quick brown fox jumps over the lazy dog"))
+	_ = p.Close()
+
+	for _, l := range strings.Split(p.String(), "\n") {
+		fmt.Printf("|%s|\n", l)
+	}
+	// Output:
+	// |  The quick   |
+	// |  brown fox   |
+	// |  jumps over  |
+	// |  the lazy    |
+	// |  dog         |
+}
@@ -4,5 +4,5 @@ go 1.13
 
 require (
 	github.com/mattn/go-runewidth v0.0.10
-	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/rivo/uniseg v0.2.0
 )
@@ -3,6 +3,7 @@ package indent
 import (
 	"bytes"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/muesli/reflow/ansi"
@@ -10,14 +11,38 @@ import (
 
 type IndentFunc func(w io.Writer)
 
+// PrefixFunc returns the prefix of the given line, starting at line 1.
+type PrefixFunc func(line int) string
+
+// WithLineNumbers returns a PrefixFunc showing the 1-based line number,
+// right-aligned within gutterWidth columns and followed by sep, e.g. "│ ".
+// Numbers wider than the gutter are shown in full, pushing the content right.
+func WithLineNumbers(gutterWidth int, sep string) PrefixFunc {
+	return func(line int) string {
+		n := strconv.Itoa(line)
+		if len(n) < gutterWidth {
+			n = strings.Repeat(" ", gutterWidth-len(n)) + n
+		}
+		return n + sep
+	}
+}
+
 type Writer struct {
 	Indent     uint
 	IndentFunc IndentFunc
+	// PrefixFunc computes a dynamic prefix per line, e.g. a line number gutter.
+	// It takes precedence over IndentFunc. Prefixes narrower than Indent are
+	// padded with spaces.
+	PrefixFunc PrefixFunc
+	// SkipBlankLines leaves lines without any printable content, including
+	// those only holding escape sequences, unindented.
+	SkipBlankLines bool
 
 	ansiWriter *ansi.Writer
 	buf        bytes.Buffer
 	skipIndent bool
 	ansi       bool
+	line       int
 }
 
 func NewWriter(indent uint, indentFunc IndentFunc) *Writer {
@@ -68,9 +93,18 @@ func (w *Writer) Write(b []byte) (int, error) {
 				w.ansi = false
 			}
 		} else {
-			if !w.skipIndent {
+			if !w.skipIndent && c == '\n' && w.SkipBlankLines {
+				// blank line
+				w.line++
+			} else if !w.skipIndent {
 				w.ansiWriter.ResetAnsi()
-				if w.IndentFunc != nil {
+				w.line++
+				if w.PrefixFunc != nil {
+					err := w.writePrefix()
+					if err != nil {
+						return 0, err
+					}
+				} else if w.IndentFunc != nil {
 					for i := 0; i < int(w.Indent); i++ {
 						w.IndentFunc(w.ansiWriter)
 					}
@@ -100,6 +134,23 @@ func (w *Writer) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+func (w *Writer) writePrefix() error {
+	prefix := w.PrefixFunc(w.line)
+	if pw := ansi.PrintableRuneWidth(prefix); pw < int(w.Indent) {
+		prefix += strings.Repeat(" ", int(w.Indent)-pw)
+	}
+
+	// the styling of the prefix isn't that of the content, so it's not tracked
+	_, err := w.ansiWriter.Forward.Write([]byte(prefix))
+	return err
+}
+
+// Close will finish the indent operation. Nothing is buffered, so it only exists
+// to satisfy the io.WriteCloser interface.
+func (w *Writer) Close() error {
+	return nil
+}
+
 // Bytes returns the indented result as a byte slice.
 func (w *Writer) Bytes() []byte {
 	return w.buf.Bytes()
@@ -3,7 +3,9 @@ package indent
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/muesli/reflow/ansi"
@@ -163,3 +165,148 @@ type fakeWriter struct{}
 func (fakeWriter) Write(_ []byte) (int, error) {
 	return 0, fakeErr
 }
+
+func TestIndentPrefixFunc(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Indent   uint
+		Prefix   PrefixFunc
+	}{
+		// Line numbers:
+		{
+			"foo\nbar\nbaz",
+			"1│ foo\n2│ bar\n3│ baz",
+			0,
+			func(line int) string {
+				return fmt.Sprintf("%d│ ", line)
+			},
+		},
+		// Narrower prefixes get padded:
+		{
+			"a\nb\nc\nd\ne\nf\ng\nh\ni\nj",
+			"1:  a\n2:  b\n3:  c\n4:  d\n5:  e\n6:  f\n7:  g\n8:  h\n9:  i\n10: j",
+			4,
+			func(line int) string {
+				return fmt.Sprintf("%d:", line)
+			},
+		},
+		// Styled prefixes are measured by their printable width:
+		{
+			"foo\nbar",
+			"\x1B[2m1\x1B[0m  foo\n\x1B[2m2\x1B[0m  bar",
+			3,
+			func(line int) string {
+				return fmt.Sprintf("\x1B[2m%d\x1B[0m", line)
+			},
+		},
+		// Styled content is kept across styled prefixes:
+		{
+			"\x1B[31mfoo\nbar",
+			"\x1B[31m\x1B[0m\x1B[2m1│\x1B[0m \x1B[31mfoo\n\x1B[0m\x1B[2m2│\x1B[0m \x1B[31mbar",
+			0,
+			func(line int) string {
+				return fmt.Sprintf("\x1B[2m%d│\x1B[0m ", line)
+			},
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Indent, nil)
+		f.PrefixFunc = tc.Prefix
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestIndentWithLineNumbers(t *testing.T) {
+	t.Parallel()
+
+	for _, lines := range []int{1, 10, 100, 1000} {
+		input := strings.TrimSuffix(strings.Repeat("foo\n", lines), "\n")
+
+		f := NewWriter(0, nil)
+		f.PrefixFunc = WithLineNumbers(4, " │ ")
+		_, err := f.Write([]byte(input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		out := strings.Split(f.String(), "\n")
+		if len(out) != lines {
+			t.Fatalf("expected %d lines, got %d", lines, len(out))
+		}
+		for i, l := range out {
+			expected := fmt.Sprintf("%4d │ foo", i+1)
+			if l != expected {
+				t.Errorf("Line %d of %d, expected %q, got %q", i+1, lines, expected, l)
+			}
+		}
+	}
+
+	// Numbers wider than the gutter aren't cut:
+	f := NewWriter(0, nil)
+	f.PrefixFunc = WithLineNumbers(1, ":")
+	_, err := f.Write([]byte(strings.Repeat("x\n", 10)))
+	if err != nil {
+		t.Error(err)
+	}
+	if expected := "1:x\n2:x\n3:x\n4:x\n5:x\n6:x\n7:x\n8:x\n9:x\n10:x\n"; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+}
+
+func TestIndentSkipBlankLines(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Prefix   PrefixFunc
+	}{
+		// Empty lines aren't indented:
+		{
+			"foo\n\nbar\n\n\nbaz\n",
+			"  foo\n\n  bar\n\n\n  baz\n",
+			nil,
+		},
+		// Lines only holding escape sequences are blank, too:
+		{
+			"\x1B[31mfoo\n\x1B[0m\nbar",
+			"\x1B[31m\x1B[0m  \x1B[31mfoo\n\x1B[0m\n  bar",
+			nil,
+		},
+		// Blank lines are still counted:
+		{
+			"foo\n\nbar",
+			"1 foo\n\n3 bar",
+			func(line int) string {
+				return fmt.Sprintf("%d ", line)
+			},
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(2, nil)
+		f.PrefixFunc = tc.Prefix
+		f.SkipBlankLines = true
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
@@ -3,24 +3,108 @@ package margin
 import (
 	"bytes"
 	"io"
+	"strings"
 
+	"github.com/muesli/reflow/ansi"
 	"github.com/muesli/reflow/indent"
 	"github.com/muesli/reflow/padding"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// Clip describes how content wider than the width between the margins is
+// kept within them.
+type Clip int
+
+const (
+	// ClipNone lets the content take its room from the right margin.
+	ClipNone Clip = iota
+	// ClipTruncate cuts lines at the width, ending them with an ellipsis.
+	ClipTruncate
+	// ClipWrap word-wraps lines at the width, breaking words wider than it.
+	ClipWrap
 )
 
 type Writer struct {
+	// Top and Bottom are the amount of blank lines added above and below
+	// the content. They span the whole width, filled like the margins.
+	Top    uint
+	Bottom uint
+	// Clip keeps content from exceeding the width between the margins. The
+	// content is processed on Close then, instead of as it's written.
+	Clip Clip
+
+	in      bytes.Buffer // content pending until Close, if clipped
+	content uint         // the width between the margins
+
 	buf bytes.Buffer
 	pw  *padding.Writer
 	iw  *indent.Writer
+	rw  *padding.Writer // adds the right margin, if any
+
+	right string // static right margin, if any
+
+	renew func() *Writer // returns a new writer with the same margins
 }
 
+// NewWriter returns a new margin-writer, indenting lines by margin and padding
+// them to the given width.
 func NewWriter(width uint, margin uint, marginFunc func(io.Writer)) *Writer {
-	pw := padding.NewWriter(width, marginFunc)
-	iw := indent.NewWriter(margin, marginFunc)
+	return NewWriterMargins(width, margin, 0, marginFunc)
+}
+
+// NewWriterMargins returns a new margin-writer with distinct left and right
+// margins. Lines are indented by the left margin and padded to the width left
+// of the right margin, which is then added. Unless clipped, content exceeding
+// the width between the margins takes its room from the right margin. With
+// ClipWrap, both margins narrow the width lines are wrapped at.
+func NewWriterMargins(width uint, left uint, right uint, marginFunc func(io.Writer)) *Writer {
+	var inner uint
+	if width > right {
+		inner = width - right
+	}
+
+	w := &Writer{
+		pw:      padding.NewWriter(inner, marginFunc),
+		iw:      indent.NewWriter(left, marginFunc),
+		content: between(inner, left),
+		renew: func() *Writer {
+			return NewWriterMargins(width, left, right, marginFunc)
+		},
+	}
+	if right > 0 {
+		w.rw = padding.NewWriter(width, marginFunc)
+	}
+	return w
+}
+
+// NewWriterStyled returns a new margin-writer using static strings as left and
+// right margins, e.g. a colored "│" as a gutter. The margins may contain ANSI
+// escape sequences and are measured by their printable width, so the content
+// starts at the same column on every line. Their styling should be reset
+// within the margin strings.
+func NewWriterStyled(width uint, left string, right string) *Writer {
+	lw := uint(ansi.PrintableRuneWidth(left))
+	rw := uint(ansi.PrintableRuneWidth(right))
+
+	var inner uint
+	if width > rw {
+		inner = width - rw
+	}
+
+	iw := indent.NewWriter(lw, nil)
+	iw.PrefixFunc = func(int) string {
+		return left
+	}
 
 	return &Writer{
-		pw: pw,
-		iw: iw,
+		pw:      padding.NewWriter(inner, nil),
+		iw:      iw,
+		right:   right,
+		content: between(inner, lw),
+		renew: func() *Writer {
+			return NewWriterStyled(width, left, right)
+		},
 	}
 }
 
@@ -40,7 +124,33 @@ func String(s string, width uint, margin uint) string {
 	return string(Bytes([]byte(s), width, margin))
 }
 
+// StringStyled is shorthand for declaring a new margin-writer instance with
+// static margins, used to immediately apply them to a string.
+func StringStyled(s string, width uint, left string, right string) string {
+	f := NewWriterStyled(width, left, right)
+	_, _ = f.Write([]byte(s))
+	f.Close()
+
+	return f.String()
+}
+
+// between returns the width left of inner after the left margin.
+func between(inner, left uint) uint {
+	if inner > left {
+		return inner - left
+	}
+	return 0
+}
+
 func (w *Writer) Write(b []byte) (int, error) {
+	if w.Clip != ClipNone {
+		return w.in.Write(b)
+	}
+	return w.write(b)
+}
+
+// write adds the margins to b.
+func (w *Writer) write(b []byte) (int, error) {
 	_, err := w.iw.Write(b)
 	if err != nil {
 		return 0, err
@@ -57,15 +167,130 @@ func (w *Writer) Write(b []byte) (int, error) {
 // Close will finish the margin operation. Always call it before trying to
 // retrieve the final result.
 func (w *Writer) Close() error {
+	if w.Clip != ClipNone {
+		clipped := w.clip(w.in.String())
+		w.in.Reset()
+		if _, err := w.write([]byte(clipped)); err != nil {
+			return err
+		}
+	}
+
+	if err := w.closeLines(); err != nil {
+		return err
+	}
+
+	if w.Top > 0 || w.Bottom > 0 {
+		w.addVerticalMargins()
+	}
+	return nil
+}
+
+// clip keeps the lines of s within the width between the margins.
+func (w *Writer) clip(s string) string {
+	if w.Clip == ClipWrap && w.content > 0 {
+		f := wordwrap.NewWriter(int(w.content))
+		f.BreakLongWords = true
+		_, _ = f.Write([]byte(s))
+		_ = f.Close()
+		return f.String()
+	}
+
+	tail := "…"
+	if w.content == 0 {
+		// not even the ellipsis fits
+		tail = ""
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = truncate.StringWithTail(l, w.content, tail)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addVerticalMargins adds the blank lines above and below the content.
+func (w *Writer) addVerticalMargins() {
+	f := w.renew()
+	_, _ = f.Write([]byte("\n"))
+	_ = f.Close()
+	blank := strings.TrimSuffix(f.String(), "\n")
+
+	body := w.buf.String()
+	trailing := strings.HasSuffix(body, "\n")
+
+	var lines []string
+	for i := uint(0); i < w.Top; i++ {
+		lines = append(lines, blank)
+	}
+	if body != "" {
+		lines = append(lines, strings.Split(strings.TrimSuffix(body, "\n"), "\n")...)
+	}
+	for i := uint(0); i < w.Bottom; i++ {
+		lines = append(lines, blank)
+	}
+
+	w.buf.Reset()
+	_, _ = w.buf.WriteString(strings.Join(lines, "\n"))
+	if trailing {
+		_ = w.buf.WriteByte('\n')
+	}
+}
+
+// closeLines finishes the margins of the content lines.
+func (w *Writer) closeLines() error {
 	err := w.pw.Close()
 	if err != nil {
 		return err
 	}
 
-	_, err = w.buf.Write(w.pw.Bytes())
+	if w.right != "" {
+		w.writeRight(w.pw.Bytes())
+		return nil
+	}
+
+	if w.rw == nil {
+		_, err = w.buf.Write(w.pw.Bytes())
+		return err
+	}
+
+	if _, err = w.rw.Write(w.pw.Bytes()); err != nil {
+		return err
+	}
+	if err = w.rw.Close(); err != nil {
+		return err
+	}
+
+	_, err = w.buf.Write(w.rw.Bytes())
 	return err
 }
 
+// writeRight appends the static right margin to every line of b, except an
+// empty trailing one. Styling still active at the end of a line is reset
+// first, so it doesn't leak into the margin.
+func (w *Writer) writeRight(b []byte) {
+	var style ansi.Style
+	lines := strings.Split(string(b), "\n")
+	for i, l := range lines {
+		if i > 0 {
+			_ = w.buf.WriteByte('\n')
+		}
+		_, _ = w.buf.WriteString(l)
+		if i == len(lines)-1 && l == "" {
+			break
+		}
+
+		ansi.Parse([]byte(l), func(seq, _ []byte) {
+			if len(seq) > 2 && seq[1] == '[' && seq[len(seq)-1] == 'm' {
+				style.Apply(string(seq[2 : len(seq)-1]))
+			}
+		})
+		if !style.IsZero() {
+			_, _ = w.buf.WriteString("\x1b[0m")
+		}
+		_, _ = w.buf.WriteString(w.right)
+	}
+}
+
 // Bytes returns the result as a byte slice.
 func (w *Writer) Bytes() []byte {
 	return w.buf.Bytes()
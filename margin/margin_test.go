@@ -2,6 +2,8 @@ package margin
 
 import (
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/muesli/reflow/indent"
@@ -123,3 +125,281 @@ type fakeWriter struct{}
 func (fakeWriter) Write(_ []byte) (int, error) {
 	return 0, fakeErr
 }
+
+func TestMarginAsymmetric(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Width    uint
+		Left     uint
+		Right    uint
+	}{
+		// Distinct margins:
+		{
+			"foo",
+			"    foo  ",
+			9,
+			4,
+			2,
+		},
+		// Multi-line:
+		{
+			"foo\nfoobar",
+			"    foo     \n    foobar  ",
+			12,
+			4,
+			2,
+		},
+		// Content exceeding the inner width takes room from the right margin:
+		{
+			"foobar",
+			"    foobar ",
+			11,
+			4,
+			2,
+		},
+		// Multi-line styled input:
+		{
+			"\x1B[31mfoo\nbar\x1B[0m",
+			"\x1B[31m\x1B[0m  \x1B[31mfoo  \x1B[0m \n\x1B[0m  \x1B[31mbar\x1B[0m   ",
+			8,
+			2,
+			1,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriterMargins(tc.Width, tc.Left, tc.Right, nil)
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestMarginStyled(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Width    uint
+		Left     string
+		Right    string
+	}{
+		// Plain gutter:
+		{
+			"foo\nfoobar",
+			"│ foo   \n│ foobar",
+			8,
+			"│ ",
+			"",
+		},
+		// Styled gutter keeps the content aligned:
+		{
+			"foo\nfoobar",
+			"\x1B[90m│\x1B[0m foo   \n\x1B[90m│\x1B[0m foobar",
+			8,
+			"\x1B[90m│\x1B[0m ",
+			"",
+		},
+		// Styled margins on both sides:
+		{
+			"foo\nfoobar",
+			"\x1B[90m│\x1B[0m foo    \x1B[90m│\x1B[0m\n\x1B[90m│\x1B[0m foobar \x1B[90m│\x1B[0m",
+			10,
+			"\x1B[90m│\x1B[0m ",
+			" \x1B[90m│\x1B[0m",
+		},
+		// Styled content is reset before the right margin:
+		{
+			"\x1B[31mfoo\nbar",
+			"\x1B[31m\x1B[0m| \x1B[31mfoo \x1B[0m|\n\x1B[0m| \x1B[31mbar \x1B[0m|",
+			7,
+			"| ",
+			"|",
+		},
+		// Styled content continues after styled margins:
+		{
+			"\x1B[31mfoo\nbar\x1B[0m",
+			"\x1B[31m\x1B[0m\x1B[34m│\x1B[0m \x1B[31mfoo   \x1B[0m \x1B[34m│\x1B[0m\n\x1B[0m\x1B[34m│\x1B[0m \x1B[31mbar\x1B[0m    \x1B[34m│\x1B[0m",
+			10,
+			"\x1B[34m│\x1B[0m ",
+			" \x1B[34m│\x1B[0m",
+		},
+		// Empty trailing lines get no margin:
+		{
+			"foo\n",
+			"> foo <\n",
+			7,
+			"> ",
+			" <",
+		},
+	}
+
+	for i, tc := range tt {
+		s := StringStyled(tc.Input, tc.Width, tc.Left, tc.Right)
+		if s != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, s)
+		}
+	}
+}
+
+func TestMarginVertical(t *testing.T) {
+	dots := func(w io.Writer) {
+		_, _ = w.Write([]byte("."))
+	}
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Writer   *Writer
+		Top      uint
+		Bottom   uint
+		Lines    int
+	}{
+		{
+			"foo",
+			"     \n     \n foo \n     ",
+			NewWriter(5, 1, nil),
+			2,
+			1,
+			4,
+		},
+		// Trailing line breaks are kept:
+		{
+			"foo\n",
+			"     \n foo \n     \n",
+			NewWriter(5, 1, nil),
+			1,
+			1,
+			3,
+		},
+		// Blank lines are filled like the margins:
+		{
+			"foo\nbar",
+			"......\n..foo.\n..bar.\n......\n......",
+			NewWriterMargins(6, 2, 1, dots),
+			1,
+			2,
+			5,
+		},
+		{
+			"foo",
+			"\x1B[90m│\x1B[0m     \x1B[90m│\x1B[0m\n\x1B[90m│\x1B[0m foo \x1B[90m│\x1B[0m",
+			NewWriterStyled(7, "\x1B[90m│\x1B[0m ", " \x1B[90m│\x1B[0m"),
+			1,
+			0,
+			2,
+		},
+		{
+			"",
+			"   \n   ",
+			NewWriter(3, 1, nil),
+			1,
+			1,
+			2,
+		},
+	}
+
+	for i, tc := range tt {
+		f := tc.Writer
+		f.Top = tc.Top
+		f.Bottom = tc.Bottom
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+		if n := len(strings.Split(strings.TrimSuffix(f.String(), "\n"), "\n")); n != tc.Lines {
+			t.Errorf("Test %d, expected %d lines, got %d", i, tc.Lines, n)
+		}
+	}
+}
+
+func TestMarginClip(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Writer   *Writer
+		Clip     Clip
+	}{
+		// Not clipped:
+		{
+			"foo supercalifragilistic",
+			"   foo supercalifragilistic",
+			NewWriterMargins(12, 3, 3, nil),
+			ClipNone,
+		},
+		// Truncated:
+		{
+			"foo supercalifragilistic\nbar",
+			"   foo s…   \n   bar      ",
+			NewWriterMargins(12, 3, 3, nil),
+			ClipTruncate,
+		},
+		{
+			"\x1B[31mfoo bar baz\x1B[0m",
+			"\x1B[31m\x1B[0m│ \x1B[31mfoo b\x1B[0m… │",
+			NewWriterStyled(10, "│ ", " │"),
+			ClipTruncate,
+		},
+		// Wrapped:
+		{
+			"foo supercalifragilistic bar",
+			"   foo      \n   superc   \n   alifra   \n   gilist   \n   ic bar   ",
+			NewWriterMargins(12, 3, 3, nil),
+			ClipWrap,
+		},
+		{
+			"\x1B[31mfoo bar baz\x1B[0m",
+			"\x1B[31m\x1B[0m│ \x1B[31mfoo\x1B[0m    │\n\x1B[31m\x1B[0m│ \x1B[31mbar\x1B[0m    │\n\x1B[31m\x1B[0m│ \x1B[31mbaz\x1B[0m    │",
+			NewWriterStyled(10, "│ ", " │"),
+			ClipWrap,
+		},
+		// The right margin makes lines wrap earlier:
+		{
+			"foo bar baz",
+			"   foo bar  \n   baz      ",
+			NewWriterMargins(12, 3, 0, nil),
+			ClipWrap,
+		},
+		{
+			"foo bar baz",
+			"   foo      \n   bar      \n   baz      ",
+			NewWriterMargins(12, 3, 3, nil),
+			ClipWrap,
+		},
+		// Nothing fits between the margins:
+		{
+			"foo\nbar\nbaz",
+			"      \n      \n",
+			NewWriterMargins(6, 3, 3, nil),
+			ClipWrap,
+		},
+	}
+
+	for i, tc := range tt {
+		f := tc.Writer
+		f.Clip = tc.Clip
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
@@ -3,7 +3,6 @@ package padding
 import (
 	"bytes"
 	"io"
-	"strings"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/muesli/reflow/ansi"
@@ -11,15 +10,36 @@ import (
 
 type PaddingFunc func(w io.Writer)
 
+// Alignment describes where the content of a line is placed within the padded
+// width.
+type Alignment int
+
+const (
+	// AlignLeft pads lines on the right.
+	AlignLeft Alignment = iota
+	// AlignRight pads lines on the left.
+	AlignRight
+	// AlignCenter pads lines on both sides. If the padding can't be split
+	// evenly, the extra cell goes to the right.
+	AlignCenter
+)
+
 type Writer struct {
 	Padding uint
 	PadFunc PaddingFunc
+	Align   Alignment
+	// Fill is repeated to fill the padding instead of spaces, e.g. "." for
+	// dot leaders. A rune not fitting the remaining cells is replaced by a
+	// space.
+	Fill string
 
 	ansiWriter *ansi.Writer
 	buf        bytes.Buffer
 	cache      bytes.Buffer
+	line       bytes.Buffer // pending line content, unless aligned left
+	fillBuf    []byte       // the padding of the current line, reused
 	lineLen    int
-	ansi       bool
+	seq        ansi.SeqState
 }
 
 func NewWriter(width uint, paddingFunc PaddingFunc) *Writer {
@@ -59,19 +79,44 @@ func String(s string, width uint) string {
 	return string(Bytes([]byte(s), width))
 }
 
+// BytesRight is shorthand for declaring a new right-aligning padding-writer
+// instance, used to immediately pad a byte slice on the left.
+func BytesRight(b []byte, width uint) []byte {
+	return bytesAligned(b, width, AlignRight)
+}
+
+// StringRight is shorthand for declaring a new right-aligning padding-writer
+// instance, used to immediately pad a string on the left.
+func StringRight(s string, width uint) string {
+	return string(BytesRight([]byte(s), width))
+}
+
+// BytesCenter is shorthand for declaring a new centering padding-writer
+// instance, used to immediately pad a byte slice on both sides.
+func BytesCenter(b []byte, width uint) []byte {
+	return bytesAligned(b, width, AlignCenter)
+}
+
+// StringCenter is shorthand for declaring a new centering padding-writer
+// instance, used to immediately pad a string on both sides.
+func StringCenter(s string, width uint) string {
+	return string(BytesCenter([]byte(s), width))
+}
+
+func bytesAligned(b []byte, width uint, align Alignment) []byte {
+	f := NewWriter(width, nil)
+	f.Align = align
+	_, _ = f.Write(b)
+	_ = f.Flush()
+
+	return f.Bytes()
+}
+
 // Write is used to write content to the padding buffer.
 func (w *Writer) Write(b []byte) (int, error) {
 	for _, c := range string(b) {
-		if c == '\x1B' {
-			// ANSI escape sequence
-			w.ansi = true
-		} else if w.ansi {
-			if (c >= 0x41 && c <= 0x5a) || (c >= 0x61 && c <= 0x7a) {
-				// ANSI sequence terminated
-				w.ansi = false
-			}
-		} else {
-			w.lineLen += runewidth.StringWidth(string(c))
+		if !w.seq.Next(c) {
+			w.lineLen += runewidth.RuneWidth(c)
 
 			if c == '\n' {
 				// end of current line
@@ -84,6 +129,12 @@ func (w *Writer) Write(b []byte) (int, error) {
 			}
 		}
 
+		if w.Align != AlignLeft && c != '\n' {
+			// the padding has to be known before the line can be written
+			_, _ = w.line.WriteRune(c)
+			continue
+		}
+
 		_, err := w.ansiWriter.Write([]byte(string(c)))
 		if err != nil {
 			return 0, err
@@ -94,20 +145,94 @@ func (w *Writer) Write(b []byte) (int, error) {
 }
 
 func (w *Writer) pad() error {
+	var left, right int
 	if w.Padding > 0 && uint(w.lineLen) < w.Padding {
-		if w.PadFunc != nil {
-			for i := 0; i < int(w.Padding)-w.lineLen; i++ {
-				w.PadFunc(w.ansiWriter)
+		n := int(w.Padding) - w.lineLen
+		switch w.Align {
+		case AlignRight:
+			left = n
+		case AlignCenter:
+			left = n / 2
+			right = n - left
+		default:
+			right = n
+		}
+	}
+
+	if err := w.padCells(left); err != nil {
+		return err
+	}
+	if err := w.flushLine(); err != nil {
+		return err
+	}
+	return w.padCells(right)
+}
+
+// padCells writes n cells of padding.
+func (w *Writer) padCells(n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if w.PadFunc != nil {
+		for i := 0; i < n; i++ {
+			w.PadFunc(w.ansiWriter)
+		}
+		return nil
+	}
+
+	_, err := w.ansiWriter.Write(w.fill(n))
+	return err
+}
+
+// fill returns n cells of the repeated Fill pattern. The returned slice is
+// only valid until the next call, as its memory gets reused for every line.
+func (w *Writer) fill(n int) []byte {
+	b := w.fillBuf[:0]
+	if w.Fill == "" || runewidth.StringWidth(w.Fill) == 0 {
+		b = appendSpaces(b, n)
+		n = 0
+	}
+
+	for n > 0 {
+		for _, c := range w.Fill {
+			rw := runewidth.RuneWidth(c)
+			if rw > n {
+				// the rune doesn't fit anymore
+				b = appendSpaces(b, n)
+				n = 0
+				break
 			}
-		} else {
-			_, err := w.ansiWriter.Write([]byte(strings.Repeat(" ", int(w.Padding)-w.lineLen)))
-			if err != nil {
-				return err
+
+			b = append(b, string(c)...)
+			n -= rw
+			if n == 0 {
+				break
 			}
 		}
 	}
 
-	return nil
+	w.fillBuf = b
+	return b
+}
+
+// appendSpaces appends n spaces to b.
+func appendSpaces(b []byte, n int) []byte {
+	for i := 0; i < n; i++ {
+		b = append(b, ' ')
+	}
+	return b
+}
+
+// flushLine writes the pending line content.
+func (w *Writer) flushLine() error {
+	if w.line.Len() == 0 {
+		return nil
+	}
+
+	_, err := w.ansiWriter.Write(w.line.Bytes())
+	w.line.Reset()
+	return err
 }
 
 // Close will finish the padding operation.
@@ -132,12 +257,17 @@ func (w *Writer) Flush() (err error) {
 		if err = w.pad(); err != nil {
 			return
 		}
+	} else if err = w.flushLine(); err != nil {
+		return
+	}
+	if err = w.ansiWriter.Flush(); err != nil {
+		return
 	}
 
 	w.cache.Reset()
 	_, err = w.buf.WriteTo(&w.cache)
 	w.lineLen = 0
-	w.ansi = false
+	w.seq = ansi.SeqState{}
 
 	return
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/muesli/reflow/ansi"
@@ -100,6 +101,39 @@ func TestPaddingString(t *testing.T) {
 	}
 }
 
+func TestPaddingStringAligned(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input  string
+		Right  string
+		Center string
+		Width  uint
+	}{
+		{"foobar", "    foobar", "  foobar  ", 10},
+		{"foo", "   foo", " foo  ", 6},
+		{"foobar", "foobar", "foobar", 4},
+		{"foo\nfoobar", "   foo\nfoobar", " foo  \nfoobar", 6},
+		{"你好", "  你好", " 你好 ", 6},
+		{"\x1B[31mfoo\x1B[0m", "   \x1B[31mfoo\x1B[0m", " \x1B[31mfoo\x1B[0m  ", 6},
+	}
+
+	for i, tc := range tt {
+		if s := StringRight(tc.Input, tc.Width); s != tc.Right {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Right, s)
+		}
+		if s := StringCenter(tc.Input, tc.Width); s != tc.Center {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Center, s)
+		}
+		if b := BytesRight([]byte(tc.Input), tc.Width); string(b) != tc.Right {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Right, b)
+		}
+		if b := BytesCenter([]byte(tc.Input), tc.Width); string(b) != tc.Center {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Center, b)
+		}
+	}
+}
+
 func BenchmarkPaddingString(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		b.ReportAllocs()
@@ -110,6 +144,16 @@ func BenchmarkPaddingString(b *testing.B) {
 	})
 }
 
+func BenchmarkPaddingLines(b *testing.B) {
+	s := strings.Repeat("foo\nfoobar\n", 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		String(s, 80)
+	}
+}
+
 func TestNewWriterPipe(t *testing.T) {
 	t.Parallel()
 
@@ -224,3 +268,181 @@ type fakeWriter struct{}
 func (fakeWriter) Write(_ []byte) (int, error) {
 	return 0, fakeErr
 }
+
+func TestPaddingAlign(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Padding  uint
+		Align    Alignment
+	}{
+		// Left alignment is the default:
+		{
+			"abc",
+			"abc   ",
+			6,
+			AlignLeft,
+		},
+		// Right alignment:
+		{
+			"abc",
+			"   abc",
+			6,
+			AlignRight,
+		},
+		{
+			"foo\nbarbaz\n",
+			"   foo\nbarbaz\n",
+			6,
+			AlignRight,
+		},
+		// Center alignment, the extra cell goes to the right:
+		{
+			"abc",
+			" abc  ",
+			6,
+			AlignCenter,
+		},
+		{
+			"ab\nabcd",
+			"  ab  \n abcd ",
+			6,
+			AlignCenter,
+		},
+		// ANSI sequences and double-width runes:
+		{
+			"\x1B[31m你好\x1B[0m",
+			"  \x1B[31m你好\x1B[0m",
+			6,
+			AlignRight,
+		},
+		{
+			"\x1B[31m你好\x1B[0m",
+			" \x1B[31m你好\x1B[0m ",
+			6,
+			AlignCenter,
+		},
+		// Hyperlinks terminated by ST:
+		{
+			"\x1B]8;;http://x.y\x1B\\ab\x1B]8;;\x1B\\\ncd",
+			"    \x1B]8;;http://x.y\x1B\\ab\x1B]8;;\x1B\\\x1B[0m\n    cd",
+			6,
+			AlignRight,
+		},
+		{
+			"\x1B]8;;http://x.y\x1B\\ab\x1B]8;;\x1B\\\ncd",
+			"  \x1B]8;;http://x.y\x1B\\ab\x1B]8;;\x1B\\  \x1B[0m\n  cd  ",
+			6,
+			AlignCenter,
+		},
+		// Trailing sequences on an empty last line are kept:
+		{
+			"foo\n\x1B[0m",
+			"   foo\n\x1B[0m",
+			6,
+			AlignRight,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Padding, nil)
+		f.Align = tc.Align
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestPaddingFill(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Padding  uint
+		Fill     string
+		Align    Alignment
+	}{
+		// Single rune:
+		{
+			"Chapter 1",
+			"Chapter 1......",
+			15,
+			".",
+			AlignLeft,
+		},
+		// Patterns are truncated to fit:
+		{
+			"foo",
+			"foo-=-=-",
+			8,
+			"-=",
+			AlignLeft,
+		},
+		{
+			"foo",
+			"-=-=-foo",
+			8,
+			"-=",
+			AlignRight,
+		},
+		{
+			"foo",
+			"-=foo-=-",
+			8,
+			"-=",
+			AlignCenter,
+		},
+		// Wide runes not fitting are replaced by spaces:
+		{
+			"foo",
+			"foo・・ ",
+			8,
+			"・",
+			AlignLeft,
+		},
+		{
+			"foo\nfoobar",
+			"foo・a・a \nfoobar・a ",
+			10,
+			"・a",
+			AlignLeft,
+		},
+		// Zero-width fills fall back to spaces:
+		{
+			"foo",
+			"foo  ",
+			5,
+			"​",
+			AlignLeft,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Padding, nil)
+		f.Fill = tc.Fill
+		f.Align = tc.Align
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
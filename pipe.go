@@ -0,0 +1,79 @@
+// Package reflow provides helpers combining the transforming writers of its
+// sub-packages.
+package reflow
+
+import (
+	"fmt"
+	"io"
+)
+
+// Pipeline feeds the content written to it through a chain of writers.
+type Pipeline struct {
+	stages []io.WriteCloser
+}
+
+type byteser interface {
+	Bytes() []byte
+}
+
+// Pipe returns a pipeline feeding its content through the given writers in
+// order, e.g. a word-wrapping, an indenting and a padding writer.
+//
+// The reflow writers buffer their whole result, so content only flows from one
+// stage to the next when the pipeline gets closed: each stage is closed in
+// order and its result, as returned by its Bytes method, is written to the
+// next stage. All but the last stage must therefore provide a Bytes method.
+func Pipe(writers ...io.WriteCloser) *Pipeline {
+	return &Pipeline{
+		stages: writers,
+	}
+}
+
+// Write writes content to the first stage of the pipeline.
+func (p *Pipeline) Write(b []byte) (int, error) {
+	if len(p.stages) == 0 {
+		return len(b), nil
+	}
+	return p.stages[0].Write(b)
+}
+
+// Close closes all stages in order, forwarding the result of each stage to the
+// next one. Always call it before trying to retrieve the final result.
+func (p *Pipeline) Close() error {
+	for i, stage := range p.stages {
+		if err := stage.Close(); err != nil {
+			return err
+		}
+		if i == len(p.stages)-1 {
+			break
+		}
+
+		b, ok := stage.(byteser)
+		if !ok {
+			return fmt.Errorf("reflow: pipeline stage %d provides no result to forward", i)
+		}
+		if _, err := p.stages[i+1].Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Bytes returns the result of the last stage as a byte slice, if it provides
+// one.
+func (p *Pipeline) Bytes() []byte {
+	if len(p.stages) == 0 {
+		return nil
+	}
+	if b, ok := p.stages[len(p.stages)-1].(byteser); ok {
+		return b.Bytes()
+	}
+	return nil
+}
+
+// String returns the result of the last stage as a string, if it provides
+// one.
+func (p *Pipeline) String() string {
+	return string(p.Bytes())
+}
@@ -0,0 +1,91 @@
+package reflow
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/muesli/reflow/indent"
+	"github.com/muesli/reflow/padding"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+func TestPipe(t *testing.T) {
+	p := Pipe(
+		wordwrap.NewWriter(10),
+		indent.NewWriter(2, nil),
+		padding.NewWriter(14, nil),
+	)
+
+	_, err := p.Write([]byte("The quick brown fox jumps over the lazy dog"))
+	if err != nil {
+		t.Error(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Error(err)
+	}
+
+	expected := "  The quick   \n  brown fox   \n  jumps over  \n  the lazy    \n  dog         "
+	if p.String() != expected {
+		t.Errorf("expected:\n\n`%q`\n\nActual Output:\n\n`%q`", expected, p.String())
+	}
+}
+
+func TestPipeEmpty(t *testing.T) {
+	p := Pipe()
+
+	if _, err := p.Write([]byte("foo")); err != nil {
+		t.Error(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Error(err)
+	}
+	if p.String() != "" {
+		t.Errorf("expected empty result, got %q", p.String())
+	}
+}
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestPipeSink(t *testing.T) {
+	sink := &nopCloser{}
+	p := Pipe(wordwrap.NewWriter(3), sink)
+
+	_, _ = p.Write([]byte("foo bar"))
+	if err := p.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if sink.String() != "foo\nbar" {
+		t.Errorf("expected sink to receive the result, got %q", sink.String())
+	}
+}
+
+var fakeErr = errors.New("fake error")
+
+type fakeWriter struct{}
+
+func (fakeWriter) Write(_ []byte) (int, error) {
+	return 0, fakeErr
+}
+
+func (fakeWriter) Close() error {
+	return fakeErr
+}
+
+func TestPipeError(t *testing.T) {
+	p := Pipe(fakeWriter{}, wordwrap.NewWriter(3))
+
+	if _, err := p.Write([]byte("foo")); err != fakeErr {
+		t.Errorf("expected fake error, got %v", err)
+	}
+	if err := p.Close(); err != fakeErr {
+		t.Errorf("expected fake error, got %v", err)
+	}
+}
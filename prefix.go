@@ -0,0 +1,28 @@
+package reflow
+
+import (
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// WrapWithPrefix word-wraps s, e.g. a log message, so it fits next to the
+// given prefix, such as a timestamp or a log level, within the limit. The
+// prefix itself is never wrapped, while all other lines hang under the first
+// column of the message. The prefix may be styled.
+func WrapWithPrefix(prefix, s string, limit int) string {
+	pw := ansi.PrintableRuneWidth(prefix)
+	width := limit - pw
+	if width < 1 {
+		width = 1
+	}
+
+	lines := wordwrap.Lines(s, width)
+	if len(lines) == 0 {
+		return prefix
+	}
+
+	indent := "\n" + strings.Repeat(" ", pw)
+	return prefix + strings.Join(lines, indent)
+}
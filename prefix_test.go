@@ -0,0 +1,54 @@
+package reflow
+
+import (
+	"testing"
+)
+
+func TestWrapWithPrefix(t *testing.T) {
+	tt := []struct {
+		Prefix   string
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		{
+			"INFO ",
+			"short message",
+			"INFO short message",
+			30,
+		},
+		{
+			"12:00:00 INFO ",
+			"the quick brown fox jumps over the lazy dog",
+			"12:00:00 INFO the quick brown\n              fox jumps over\n              the lazy dog",
+			30,
+		},
+		// Styled prefixes:
+		{
+			"\x1B[2m12:00\x1B[0m \x1B[31mERROR\x1B[0m ",
+			"connection refused by peer",
+			"\x1B[2m12:00\x1B[0m \x1B[31mERROR\x1B[0m connection\n            refused by\n            peer",
+			22,
+		},
+		// Line breaks within the message hang, too:
+		{
+			"> ",
+			"foo\nbar baz",
+			"> foo\n  bar\n  baz",
+			6,
+		},
+		{
+			"WARN ",
+			"",
+			"WARN ",
+			10,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := WrapWithPrefix(tc.Prefix, tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package truncate
 import (
 	"bytes"
 	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
 
@@ -10,12 +13,30 @@ import (
 )
 
 type Writer struct {
+	// FromLeft truncates the leading content instead of the trailing one,
+	// prepending the tail. The content is buffered until Close then, as its
+	// whole width decides what gets cut.
+	FromLeft bool
+	// Pad appends spaces to content narrower than the width, so the result
+	// is always exactly as wide as the width. Uncut content is padded on
+	// Close.
+	Pad bool
+	// WordBoundary cuts at the last space in front of the cut instead of
+	// within a word, unless that would leave nothing but the tail. It doesn't
+	// apply to FromLeft.
+	WordBoundary bool
+
 	width uint
 	tail  string
 
 	ansiWriter *ansi.Writer
 	buf        bytes.Buffer
 	ansi       bool
+	written    uint         // the printable width of the content written so far
+	cut        bool         // the content got cut, so further writes are dropped
+	dropped    uint         // the printable width of the content cut off
+	partial    []byte       // an incomplete escape sequence or rune ending the last write
+	in         bytes.Buffer // content pending until Close, if FromLeft
 }
 
 func NewWriter(width uint, tail string) *Writer {
@@ -51,6 +72,7 @@ func Bytes(b []byte, width uint) []byte {
 func BytesWithTail(b []byte, width uint, tail []byte) []byte {
 	f := NewWriter(width, string(tail))
 	_, _ = f.Write(b)
+	_ = f.Close()
 
 	return f.Bytes()
 }
@@ -68,44 +90,368 @@ func StringWithTail(s string, width uint, tail string) string {
 	return string(BytesWithTail([]byte(s), width, []byte(tail)))
 }
 
+// StringWithInfo truncates a string like StringWithTail, additionally
+// returning the printable cell width of the removed content. It is 0 if the
+// string didn't get truncated.
+func StringWithInfo(s string, width uint, tail string) (string, int) {
+	f := NewWriter(width, tail)
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.String(), int(f.dropped)
+}
+
+// Fit truncates a string like String, but pads it with spaces if it's
+// narrower than the width. The result is always exactly as wide as the width.
+func Fit(s string, width uint) string {
+	return FitWithTail(s, width, "")
+}
+
+// FitWithTail truncates a string like StringWithTail, but pads it with spaces
+// if it's narrower than the width. The result is always exactly as wide as the
+// width.
+func FitWithTail(s string, width uint, tail string) string {
+	f := NewWriter(width, tail)
+	f.Pad = true
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.String()
+}
+
+// BytesLeft is shorthand for declaring a new default truncate-writer instance,
+// used to immediately truncate a byte slice from the left.
+func BytesLeft(b []byte, width uint) []byte {
+	return BytesLeftWithTail(b, width, []byte(""))
+}
+
+// BytesLeftWithTail is shorthand for declaring a new default truncate-writer
+// instance, used to immediately truncate a byte slice from the left. A tail is
+// then added to the beginning of the byte slice.
+func BytesLeftWithTail(b []byte, width uint, tail []byte) []byte {
+	f := NewWriter(width, string(tail))
+	f.FromLeft = true
+	_, _ = f.Write(b)
+	_ = f.Close()
+
+	return f.Bytes()
+}
+
+// StringLeft is shorthand for declaring a new default truncate-writer instance,
+// used to immediately truncate a string from the left.
+func StringLeft(s string, width uint) string {
+	return StringLeftWithTail(s, width, "")
+}
+
+// StringLeftWithTail is shorthand for declaring a new default truncate-writer
+// instance, used to immediately truncate a string from the left. A tail is then
+// added to the beginning of the string.
+func StringLeftWithTail(s string, width uint, tail string) string {
+	return string(BytesLeftWithTail([]byte(s), width, []byte(tail)))
+}
+
+// StringMiddle truncates the middle of a string, so that its head and tail,
+// joined by the given tail string, fit the given printable cell width. The
+// width is split evenly between head and tail. The styling active across the
+// removed middle is restored for the tail.
+func StringMiddle(s string, width uint, tail string) string {
+	return string(BytesMiddle([]byte(s), width, []byte(tail)))
+}
+
+// BytesMiddle truncates the middle of a byte slice, so that its head and
+// tail, joined by the given tail, fit the given printable cell width.
+func BytesMiddle(b []byte, width uint, tail []byte) []byte {
+	total := ansi.PrintableRuneWidth(string(b))
+	if uint(total) <= width {
+		return b
+	}
+	tw := ansi.PrintableRuneWidth(string(tail))
+	if width < uint(tw) {
+		return tail
+	}
+
+	var buf bytes.Buffer
+	aw := &ansi.Writer{Forward: &buf}
+
+	// the head gets the extra cell for odd widths
+	avail := int(width) - tw
+	headWidth := (avail + 1) / 2
+	tailWidth := avail
+
+	var pos int
+	var inTail bool
+	ansi.Parse(b, func(seq, text []byte) {
+		if seq != nil {
+			// sequences are kept, even within the removed middle
+			_, _ = aw.Write(seq)
+			return
+		}
+
+		for _, c := range string(text) {
+			rw := runewidth.RuneWidth(c)
+			if !inTail && pos+rw > headWidth {
+				// end of the head
+				inTail = true
+				tailWidth = avail - pos
+				if aw.LastSequence() != "" {
+					aw.ResetAnsi()
+				}
+				_, _ = buf.Write(tail)
+				aw.RestoreAnsi()
+			}
+			pos += rw
+			if inTail && total-pos+rw > tailWidth {
+				// within the removed middle
+				continue
+			}
+			_, _ = aw.Write([]byte(string(c)))
+		}
+	})
+
+	return buf.Bytes()
+}
+
+// Lines keeps the first maxLines lines of a string. If any lines were dropped,
+// the last kept line ends with the given indicator instead of its last
+// characters, keeping its printable width. Styling still active at the cut
+// gets reset.
+func Lines(s string, maxLines uint, indicator string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if uint(len(lines)) <= maxLines {
+		return s
+	}
+	if maxLines == 0 {
+		return ""
+	}
+
+	lines = lines[:maxLines]
+	last := lines[len(lines)-1]
+	lines[len(lines)-1] = StringWithTail(last, uint(ansi.PrintableRuneWidth(last)), indicator)
+
+	out := strings.Join(lines, "\n")
+	if !ansi.ActiveStyle(out).IsZero() {
+		out += "\x1b[0m"
+	}
+	return out
+}
+
 // Write truncates content at the given printable cell width, leaving any
-// ansi sequences intact.
+// ansi sequences intact. Escape sequences are never cut, all of them preceding
+// the cut are kept. An escape sequence or rune split across writes is held
+// back until it's complete.
 func (w *Writer) Write(b []byte) (int, error) {
+	if w.FromLeft {
+		return w.in.Write(b)
+	}
+
+	n := len(b)
+	if len(w.partial) > 0 {
+		b = append(w.partial, b...)
+		w.partial = nil
+	}
+	i := ansi.Incomplete(b)
+	w.partial = append(w.partial, b[i:]...)
+
+	if _, err := w.write(b[:i]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// write truncates complete content.
+func (w *Writer) write(b []byte) (int, error) {
+	if w.cut {
+		w.dropped += uint(ansi.PrintableRuneWidth(string(b)))
+		return len(b), nil
+	}
+
 	tw := ansi.PrintableRuneWidth(w.tail)
 	if w.width < uint(tw) {
+		w.cut = true
+		w.dropped += uint(ansi.PrintableRuneWidth(string(b)))
 		return w.buf.WriteString(w.tail)
 	}
 
-	w.width -= uint(tw)
-	var curWidth uint
+	width := w.width - uint(tw)
+	end, curWidth := w.cutOffset(b, width-w.written)
+	curWidth += w.written
+	w.written = curWidth
+	cut := end < len(b)
+	var pos int
+	var err error
+
+	ansi.Parse(b, func(seq, text []byte) {
+		if pos >= end || err != nil {
+			return
+		}
+		if seq != nil {
+			pos += len(seq)
+			err = w.writeSequence(seq)
+			return
+		}
+
+		if pos+len(text) > end {
+			text = text[:end-pos]
+		}
+		pos += len(text)
+		_, err = w.ansiWriter.Write(text)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if cut {
+		w.cut = true
+		w.dropped += uint(ansi.PrintableRuneWidth(string(b[end:])))
+		if w.ansiWriter.LastSequence() != "" {
+			w.ansiWriter.ResetAnsi()
+		}
+		gap := int(width - curWidth)
+		if tw > 0 && !w.WordBoundary {
+			// a cut double-width rune leaves a gap, keep the tail
+			// aligned to the given width
+			_, _ = w.buf.WriteString(strings.Repeat(" ", gap))
+			gap = 0
+		}
+		n, err := w.buf.WriteString(w.tail)
+		if err != nil || !w.Pad {
+			return n, err
+		}
+		return n, w.pad(gap)
+	}
+
+	return len(b), nil
+}
 
-	for _, c := range string(b) {
-		if c == '\x1B' {
-			// ANSI escape sequence
-			w.ansi = true
-		} else if w.ansi {
-			if (c >= 0x41 && c <= 0x5a) || (c >= 0x61 && c <= 0x7a) {
-				// ANSI sequence terminated
-				w.ansi = false
+// cutOffset returns the byte offset the content of b has to be cut at to fit
+// the given width, or len(b) if it fits, along with the printable cell width
+// in front of it.
+func (w *Writer) cutOffset(b []byte, limit uint) (int, uint) {
+	cut := -1
+	var pos int
+	var width uint
+
+	ansi.Parse(b, func(seq, text []byte) {
+		if cut >= 0 {
+			return
+		}
+		for i, c := range string(text) {
+			rw := uint(runewidth.RuneWidth(c))
+			if width+rw > limit {
+				cut = pos + i
+				return
 			}
-		} else {
-			curWidth += uint(runewidth.RuneWidth(c))
+			width += rw
+		}
+		pos += len(seq) + len(text)
+	})
+	if cut < 0 {
+		return len(b), width
+	}
+
+	if w.WordBoundary {
+		if i := wordBoundary(b, cut); i > 0 {
+			return i, uint(ansi.PrintableRuneWidth(string(b[:i])))
 		}
+	}
+	return cut, width
+}
+
+// wordBoundary returns the offset of the spaces in front of the word cut at
+// the given offset of b, or 0 if there are none with content before them. The
+// cut offset itself is returned if it doesn't fall within a word.
+func wordBoundary(b []byte, cut int) int {
+	if c, _ := utf8.DecodeRune(b[cut:]); unicode.IsSpace(c) {
+		// between words already
+		return trimSpace(b, cut)
+	}
 
-		if curWidth > w.width {
-			if w.ansiWriter.LastSequence() != "" {
-				w.ansiWriter.ResetAnsi()
+	var boundary, pos int
+	var content bool
+	ansi.Parse(b[:cut], func(seq, text []byte) {
+		for i, c := range string(text) {
+			if !unicode.IsSpace(c) {
+				content = true
+			} else if content {
+				boundary = pos + i
 			}
-			return w.buf.WriteString(w.tail)
 		}
+		pos += len(seq) + len(text)
+	})
 
-		_, err := w.ansiWriter.Write([]byte(string(c)))
-		if err != nil {
-			return 0, err
+	return trimSpace(b, boundary)
+}
+
+// trimSpace moves the offset of b in front of the spaces preceding it.
+func trimSpace(b []byte, offset int) int {
+	var end, pos int
+	ansi.Parse(b[:offset], func(seq, text []byte) {
+		for i, c := range string(text) {
+			if !unicode.IsSpace(c) {
+				end = pos + i + utf8.RuneLen(c)
+			}
 		}
+		pos += len(seq) + len(text)
+	})
+
+	if end == 0 {
+		// nothing but spaces
+		return offset
 	}
+	return end
+}
 
-	return len(b), nil
+// pad writes n spaces.
+func (w *Writer) pad(n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	_, err := w.ansiWriter.Forward.Write([]byte(strings.Repeat(" ", n)))
+	return err
+}
+
+// writeSequence writes a complete escape sequence. SGR sequences are passed
+// through the ansi writer, so their styling can be reset at the cut.
+func (w *Writer) writeSequence(seq []byte) error {
+	if bytes.HasPrefix(seq, []byte("\x1b[")) && seq[len(seq)-1] == 'm' {
+		_, err := w.ansiWriter.Write(seq)
+		return err
+	}
+
+	_, err := w.ansiWriter.Forward.Write(seq)
+	return err
+}
+
+// WidthSoFar returns the printable cell width of the content written so far,
+// not counting the tail. Once the content got cut, it doesn't grow anymore, so
+// there's no need to write any more.
+func (w *Writer) WidthSoFar() int {
+	return int(w.written)
+}
+
+// Close will finish the truncate operation, writing an escape sequence or rune
+// which is still incomplete as it is. Content which didn't get cut is padded
+// to the width then, if Pad is set.
+func (w *Writer) Close() error {
+	if w.FromLeft {
+		_, err := w.writeLeft(w.in.Bytes())
+		w.in.Reset()
+		return err
+	}
+
+	if len(w.partial) > 0 {
+		b := w.partial
+		w.partial = nil
+		if _, err := w.write(b); err != nil {
+			return err
+		}
+	}
+
+	if w.Pad && !w.cut {
+		return w.pad(int(w.width) - int(w.written))
+	}
+	return nil
 }
 
 // Bytes returns the truncated result as a byte slice.
@@ -117,3 +463,86 @@ func (w *Writer) Bytes() []byte {
 func (w *Writer) String() string {
 	return w.buf.String()
 }
+
+// writeLeft truncates the leading content to fit the given printable cell
+// width. All ansi sequences are kept, so the styling active at the cut point
+// is preserved.
+func (w *Writer) writeLeft(b []byte) (int, error) {
+	tw := ansi.PrintableRuneWidth(w.tail)
+	if w.width < uint(tw) {
+		return w.buf.WriteString(w.tail)
+	}
+
+	s := string(b)
+	width := ansi.PrintableRuneWidth(s)
+	if uint(width) <= w.width {
+		if _, err := w.ansiWriter.Forward.Write(b); err != nil {
+			return 0, err
+		}
+		w.written = uint(width)
+		if w.Pad {
+			if err := w.pad(int(w.width) - width); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+
+	// the width of the printable content to drop
+	drop := width - (int(w.width) - tw)
+	var dropped int
+	var err error
+
+	tail := w.tail
+	if tw > 0 {
+		// a cut double-width rune leaves a gap, keep the tail aligned to
+		// the start
+		tail += strings.Repeat(" ", overshoot(s, drop))
+	}
+	if _, err = w.ansiWriter.Write([]byte(tail)); err != nil {
+		return 0, err
+	}
+
+	ansi.Parse(b, func(seq, text []byte) {
+		if err != nil {
+			return
+		}
+		if seq != nil {
+			err = w.writeSequence(seq)
+			return
+		}
+
+		start := len(text)
+		for i, c := range string(text) {
+			if dropped >= drop {
+				start = i
+				break
+			}
+			dropped += runewidth.RuneWidth(c)
+		}
+		_, err = w.ansiWriter.Write(text[start:])
+	})
+	if err != nil {
+		return 0, err
+	}
+	w.written = uint(width - dropped)
+
+	return len(b), nil
+}
+
+// overshoot returns by how many cells the leading runes of s, whose printable
+// width is at least drop, exceed drop.
+func overshoot(s string, drop int) int {
+	var dropped int
+
+	ansi.Parse([]byte(s), func(_, text []byte) {
+		for _, c := range string(text) {
+			if dropped >= drop {
+				return
+			}
+			dropped += runewidth.RuneWidth(c)
+		}
+	})
+
+	return dropped - drop
+}
@@ -178,3 +178,728 @@ type fakeWriter struct{}
 func (fakeWriter) Write(_ []byte) (int, error) {
 	return 0, fakeErr
 }
+
+func TestTruncateLeft(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		in       string
+		expected string
+	}{
+		// No-op, should pass through:
+		{
+			10,
+			"",
+			"foo",
+			"foo",
+		},
+		// Basic truncate:
+		{
+			3,
+			"",
+			"foobar",
+			"bar",
+		},
+		// Truncate with tail:
+		{
+			4,
+			"…",
+			"foobar",
+			"…bar",
+		},
+		// Tail is longer than width:
+		{
+			2,
+			"...",
+			"foo",
+			"...",
+		},
+		// Double-width runes:
+		{
+			4,
+			"",
+			"你好世界",
+			"世界",
+		},
+		// Double-width rune is dropped if it is cut:
+		{
+			3,
+			"",
+			"你好世界",
+			"界",
+		},
+		{
+			5,
+			"…",
+			"你好世界",
+			"…世界",
+		},
+		// Multi-byte runes:
+		{
+			3,
+			"",
+			"äöüäöü",
+			"äöü",
+		},
+		// Styling active at the cut point is kept:
+		{
+			4,
+			"",
+			"\x1B[31mfoo\x1B[0mbar",
+			"\x1B[31mo\x1B[0mbar",
+		},
+		{
+			4,
+			"…",
+			"\x1B[31mfoobar\x1B[0m",
+			"…\x1B[31mbar\x1B[0m",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.width, tc.tail)
+		f.FromLeft = true
+
+		_, err := f.Write([]byte(tc.in))
+		if err != nil {
+			t.Error(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.expected, f.String())
+		}
+	}
+
+	// The content is cut as a whole, not per write:
+	f := NewWriter(5, "…")
+	f.FromLeft = true
+	for _, s := range []string{"abcdef", "ghij"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+	if f.String() != "…ghij" {
+		t.Errorf("expected %q, got %q", "…ghij", f.String())
+	}
+}
+
+func TestTruncateStringLeft(t *testing.T) {
+	t.Parallel()
+
+	actual := StringLeftWithTail("deep/path/file.go", 10, "…")
+	expected := "…h/file.go"
+	if actual != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, actual)
+	}
+
+	actual = StringLeft("foobar", 3)
+	expected = "bar"
+	if actual != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, actual)
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		in       string
+		expected string
+	}{
+		// No-op, should pass through:
+		{
+			10,
+			"…",
+			"foo",
+			"foo",
+		},
+		// Odd width:
+		{
+			7,
+			"…",
+			"verylongname",
+			"ver…ame",
+		},
+		// Even width, the head gets the extra cell:
+		{
+			8,
+			"…",
+			"verylongname",
+			"very…ame",
+		},
+		// Tail is longer than width:
+		{
+			2,
+			"...",
+			"foobar",
+			"...",
+		},
+		// Double-width runes:
+		{
+			7,
+			"…",
+			"你好世界你好",
+			"你…你好",
+		},
+		{
+			6,
+			"…",
+			"你好世界你好",
+			"你…好",
+		},
+		// Styling is restored for the tail:
+		{
+			5,
+			"…",
+			"\x1B[31mfoobarbaz\x1B[0m",
+			"\x1B[31mfo\x1B[0m…\x1B[31maz\x1B[0m",
+		},
+		// Styling changed within the middle:
+		{
+			5,
+			"…",
+			"\x1B[31mfoo\x1B[32mbar\x1B[0m",
+			"\x1B[31mfo\x1B[0m…\x1B[31m\x1B[32mar\x1B[0m",
+		},
+		// Hyperlinks are neither cut nor measured:
+		{
+			7,
+			"…",
+			"\x1B]8;;https://example.com\x1B\\example.com\x1B]8;;\x1B\\",
+			"\x1B]8;;https://example.com\x1B\\exa…com\x1B]8;;\x1B\\",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := StringMiddle(tc.in, tc.width, tc.tail)
+		if actual != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.expected, actual)
+		}
+		if w := ansi.PrintableRuneWidth(actual); uint(w) > tc.width && tc.width >= uint(ansi.PrintableRuneWidth(tc.tail)) {
+			t.Errorf("Test %d, width %d exceeds %d", i, w, tc.width)
+		}
+	}
+}
+
+func TestTruncateStringWithInfo(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		in       string
+		expected string
+		dropped  int
+	}{
+		// No-op:
+		{
+			10,
+			"…",
+			"foo",
+			"foo",
+			0,
+		},
+		// Basic truncate:
+		{
+			3,
+			"",
+			"foobar",
+			"foo",
+			3,
+		},
+		// Truncate with tail:
+		{
+			4,
+			"…",
+			"foobar",
+			"foo…",
+			3,
+		},
+		// Tail is longer than width:
+		{
+			2,
+			"...",
+			"foo",
+			"...",
+			3,
+		},
+		// Double-width runes:
+		{
+			3,
+			"",
+			"你好世界",
+			"你",
+			6,
+		},
+		{
+			4,
+			"…",
+			"你好世界",
+			"你 …",
+			6,
+		},
+		// ANSI sequences don't count:
+		{
+			4,
+			"…",
+			"\x1B[38;2;249;38;114mfoobar\x1B[0m",
+			"\x1B[38;2;249;38;114mfoo\x1B[0m…",
+			3,
+		},
+		{
+			10,
+			"…",
+			"\x1B[38;2;249;38;114mfoobar\x1B[0m",
+			"\x1B[38;2;249;38;114mfoobar\x1B[0m",
+			0,
+		},
+	}
+
+	for i, tc := range tt {
+		actual, dropped := StringWithInfo(tc.in, tc.width, tc.tail)
+		if actual != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.expected, actual)
+		}
+		if dropped != tc.dropped {
+			t.Errorf("Test %d, expected %d dropped cells, got %d", i, tc.dropped, dropped)
+		}
+	}
+}
+
+func TestTruncateDoubleWidth(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		fromLeft bool
+		expected string
+	}{
+		{3, "", false, "你"},
+		{4, "", false, "你好"},
+		{5, "", false, "你好"},
+		{3, "…", false, "你…"},
+		{4, "…", false, "你 …"},
+		{5, "…", false, "你好…"},
+		{3, "", true, "界"},
+		{4, "", true, "世界"},
+		{5, "", true, "世界"},
+		{3, "…", true, "…界"},
+		{4, "…", true, "… 界"},
+		{5, "…", true, "…世界"},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.width, tc.tail)
+		f.FromLeft = tc.fromLeft
+
+		_, err := f.Write([]byte("你好世界"))
+		if err != nil {
+			t.Error(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.expected, f.String())
+		}
+		if w := ansi.PrintableRuneWidth(f.String()); uint(w) > tc.width {
+			t.Errorf("Test %d, width %d exceeds %d", i, w, tc.width)
+		}
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		maxLines  uint
+		indicator string
+		in        string
+		expected  string
+	}{
+		// Fewer lines:
+		{
+			3,
+			"…",
+			"foo\nbar",
+			"foo\nbar",
+		},
+		// Equal lines:
+		{
+			2,
+			"…",
+			"foo\nbar",
+			"foo\nbar",
+		},
+		{
+			2,
+			"…",
+			"foo\nbar\n",
+			"foo\nbar\n",
+		},
+		// More lines:
+		{
+			2,
+			"…",
+			"foo\nbar\nbaz",
+			"foo\nba…",
+		},
+		{
+			1,
+			"...",
+			"foo bar\nbaz\nqux",
+			"foo ...",
+		},
+		// Empty last line:
+		{
+			2,
+			"…",
+			"foo\n\nbar",
+			"foo\n…",
+		},
+		// No lines:
+		{
+			0,
+			"…",
+			"foo\nbar",
+			"",
+		},
+		// Styling spanning the cut is reset:
+		{
+			2,
+			"…",
+			"\x1B[31mfoo\nbar\nbaz\x1B[0m",
+			"\x1B[31mfoo\nba…\x1B[0m",
+		},
+		{
+			1,
+			"…",
+			"\x1B[31mfoo\nbar\x1B[0m",
+			"\x1B[31mfo\x1B[0m…",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := Lines(tc.in, tc.maxLines, tc.indicator)
+		if actual != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.expected, actual)
+		}
+	}
+}
+
+func TestTruncateBufferParity(t *testing.T) {
+	t.Parallel()
+
+	for i, in := range []string{
+		"foobar",
+		"你好世界",
+		"\x1B[38;2;249;38;114m你好\x1B[0m",
+		"\x1B[7m--",
+		"\x1B[31mfoo\x1B[0mbar",
+		"\x1B[1mfoo \x1B[32mbar\x1B[0m baz",
+		"foo\x1B[31mbar",
+	} {
+		for width := uint(0); width <= 10; width++ {
+			var b ansi.Buffer
+			b.WriteString(in)
+			b.TruncateWidth(int(width))
+
+			if expected := String(in, width); b.String() != expected {
+				t.Errorf("Test %d, width %d, expected %q, got %q", i, width, expected, b.String())
+			}
+		}
+	}
+}
+
+func TestTruncateEscapes(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		in       string
+		expected string
+	}{
+		// Tail right after a color code:
+		{
+			4,
+			"…",
+			"foo\x1B[31mbar",
+			"foo\x1B[31m\x1B[0m…",
+		},
+		// Hyperlinks aren't measured:
+		{
+			5,
+			"…",
+			"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\x1B\\",
+			"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\x1B\\",
+		},
+		{
+			3,
+			"…",
+			"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\x1B\\",
+			"\x1B]8;;https://example.com\x1B\\li…",
+		},
+		// Sequences ending in other final bytes:
+		{
+			3,
+			"",
+			"a\x1B[3~bcd",
+			"a\x1B[3~bc",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.width, tc.tail)
+
+		_, err := f.Write([]byte(tc.in))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.expected, f.String())
+		}
+	}
+
+	inputs := []string{
+		"\x1B[38;2;249;38;114mfoo\x1B[0m \x1B[1mbar\x1B[22m baz",
+		"\x1B]8;;https://example.com\x1B\\link\x1B]8;;\x1B\\ \x1B_Gf=100;AAAA\x1B\\text",
+		"你\x1B[31m好\x1B[3~世\x1B[0m界",
+	}
+	for _, in := range inputs {
+		seqs := map[string]bool{"\x1B[0m": true}
+		ansi.Parse([]byte(in), func(seq, _ []byte) {
+			seqs[string(seq)] = true
+		})
+
+		for width := uint(0); width <= uint(ansi.PrintableRuneWidth(in)); width++ {
+			for _, fromLeft := range []bool{false, true} {
+				f := NewWriter(width, "…")
+				f.FromLeft = fromLeft
+				_, _ = f.Write([]byte(in))
+				_ = f.Close()
+
+				ansi.Parse(f.Bytes(), func(seq, _ []byte) {
+					if seq != nil && !seqs[string(seq)] {
+						t.Errorf("%q truncated to %d (from left: %t) contains the partial sequence %q", in, width, fromLeft, seq)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestTruncatePad(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		fromLeft bool
+		in       string
+		expected string
+	}{
+		// Shorter than the width:
+		{6, "", false, "foo", "foo   "},
+		{6, "…", false, "\x1B[31mfoo\x1B[0m", "\x1B[31mfoo\x1B[0m   "},
+		{6, "…", true, "\x1B[31mfoo\x1B[0m", "\x1B[31mfoo\x1B[0m   "},
+		{6, "", false, "你好", "你好  "},
+		// As wide as the width:
+		{6, "", false, "foobar", "foobar"},
+		{6, "", true, "\x1B[31mfoobar\x1B[0m", "\x1B[31mfoobar\x1B[0m"},
+		// Wider than the width:
+		{4, "", false, "\x1B[31mfoobar\x1B[0m", "\x1B[31mfoob\x1B[0m"},
+		{4, "…", false, "\x1B[31mfoobar\x1B[0m", "\x1B[31mfoo\x1B[0m…"},
+		{4, "…", true, "\x1B[31mfoobar\x1B[0m", "…\x1B[31mbar\x1B[0m"},
+		{4, "", false, "你好世界", "你好"},
+		{0, "", false, "foo", ""},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.width, tc.tail)
+		f.FromLeft = tc.fromLeft
+		f.Pad = true
+
+		_, err := f.Write([]byte(tc.in))
+		if err != nil {
+			t.Error(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.expected, f.String())
+		}
+		if n := ansi.PrintableRuneWidth(f.String()); n != int(tc.width) {
+			t.Errorf("Test %d, expected a width of %d, got %d", i, tc.width, n)
+		}
+	}
+
+	// Content written in chunks is padded once:
+	f := NewWriter(6, "")
+	f.Pad = true
+	for _, s := range []string{"ab", "cd"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+	if f.String() != "abcd  " {
+		t.Errorf("expected %q, got %q", "abcd  ", f.String())
+	}
+
+	if s := Fit("foo", 5); s != "foo  " {
+		t.Errorf("expected %q, got %q", "foo  ", s)
+	}
+	if s := FitWithTail("foobar", 5, "…"); s != "foob…" {
+		t.Errorf("expected %q, got %q", "foob…", s)
+	}
+}
+
+func TestTruncateWordBoundary(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width        uint
+		tail         string
+		in           string
+		midWord      string
+		wordBoundary string
+	}{
+		{10, "", "the quick brown fox", "the quick ", "the quick"},
+		{10, "...", "the quick brown fox", "the qui...", "the..."},
+		{10, "…", "the quick brown fox", "the quick…", "the quick…"},
+		// Fits anyway:
+		{20, "…", "the quick brown fox", "the quick brown fox", "the quick brown fox"},
+		// Cut right in front of a space:
+		{9, "", "the quick brown fox", "the quick", "the quick"},
+		{9, "", "the quick   brown", "the quick", "the quick"},
+		// Nothing but the tail would be left:
+		{6, "…", "thequick brown", "thequ…", "thequ…"},
+		{6, "…", "  thequick brown", "  the…", "  the…"},
+		// Escape sequences are kept:
+		{
+			10, "…",
+			"\x1B[31mthe\x1B[0m \x1B[1mquick brown\x1B[0m",
+			"\x1B[31mthe\x1B[0m \x1B[1mquick\x1B[0m…",
+			"\x1B[31mthe\x1B[0m \x1B[1mquick\x1B[0m…",
+		},
+		{
+			8, "…",
+			"\x1B[31mthe\x1B[0m \x1B[1mquick brown\x1B[0m",
+			"\x1B[31mthe\x1B[0m \x1B[1mqui\x1B[0m…",
+			"\x1B[31mthe\x1B[0m…",
+		},
+		{
+			8, "…",
+			"\x1B[31mthe quick brown\x1B[0m",
+			"\x1B[31mthe qui\x1B[0m…",
+			"\x1B[31mthe\x1B[0m…",
+		},
+	}
+
+	for i, tc := range tt {
+		for _, wordBoundary := range []bool{false, true} {
+			f := NewWriter(tc.width, tc.tail)
+			f.WordBoundary = wordBoundary
+
+			_, err := f.Write([]byte(tc.in))
+			if err != nil {
+				t.Error(err)
+			}
+
+			expected := tc.midWord
+			if wordBoundary {
+				expected = tc.wordBoundary
+			}
+			if f.String() != expected {
+				t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, expected, f.String())
+			}
+		}
+	}
+}
+
+func TestTruncateWidthSoFar(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		width    uint
+		tail     string
+		chunks   []string
+		widths   []int
+		expected string
+	}{
+		{
+			10, "",
+			[]string{"foo", " bar", " baz", " qux"},
+			[]int{3, 7, 10, 10},
+			"foo bar ba",
+		},
+		{
+			10, "…",
+			[]string{"foo", " bar", " baz", " qux"},
+			[]int{3, 7, 9, 9},
+			"foo bar b…",
+		},
+		// Escape sequences don't count:
+		{
+			6, "",
+			[]string{"\x1B[31mfoo", "\x1B[0m 你", "好"},
+			[]int{3, 6, 6},
+			"\x1B[31mfoo\x1B[0m 你",
+		},
+		// Escape sequences and runes split across writes:
+		{
+			5, "",
+			[]string{"\x1B[3", "1mabcdef"},
+			[]int{0, 5},
+			"\x1B[31mabcde\x1B[0m",
+		},
+		{
+			6, "",
+			[]string{"你\xE5", "\xA5\xBD世界"},
+			[]int{2, 6},
+			"你好世",
+		},
+		// Only the tail fits:
+		{
+			1, "…",
+			[]string{"foo", "bar"},
+			[]int{0, 0},
+			"…",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.width, tc.tail)
+		for j, c := range tc.chunks {
+			_, err := f.Write([]byte(c))
+			if err != nil {
+				t.Error(err)
+			}
+			if f.WidthSoFar() != tc.widths[j] {
+				t.Errorf("Test %d, expected a width of %d after chunk %d, got %d", i, tc.widths[j], j, f.WidthSoFar())
+			}
+		}
+
+		if f.String() != tc.expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.expected, f.String())
+		}
+	}
+}
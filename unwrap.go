@@ -0,0 +1,80 @@
+package reflow
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Unwrap rejoins lines of text which got hard-wrapped before, so it can be
+// wrapped to a new width. A line is joined to the previous one with a single
+// space, unless the previous line is blank or ends a sentence, or the line
+// starts a list item. Lines quoted as in emails, e.g. "> ", are only joined to
+// lines of the same quoting depth, dropping their own quote markers.
+func Unwrap(s string) string {
+	var lines []string
+	var joinable bool
+	var depth int
+	for _, l := range strings.Split(s, "\n") {
+		d, content := splitQuote(l)
+		text := strings.TrimSpace(content)
+
+		if joinable && text != "" && d == depth && !isListItem(text) {
+			last := len(lines) - 1
+			lines[last] = strings.TrimRight(lines[last], " \t") + " " + text
+		} else {
+			lines = append(lines, l)
+		}
+
+		joinable = text != "" && !endsSentence(text)
+		depth = d
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// splitQuote returns the quoting depth of an email-style quoted line, such as
+// "> > foo", and the content following the quote markers.
+func splitQuote(l string) (int, string) {
+	var depth, end int
+	for i := 0; i < len(l); i++ {
+		if l[i] == '>' {
+			depth++
+			end = i + 1
+		} else if l[i] != ' ' && l[i] != '\t' {
+			break
+		}
+	}
+
+	return depth, l[end:]
+}
+
+// isListItem reports whether the text starts with a bullet, such as "- " or
+// "* ", or with a number followed by "." or ")".
+func isListItem(text string) bool {
+	for _, bullet := range []string{"- ", "* ", "+ ", "• "} {
+		if strings.HasPrefix(text, bullet) {
+			return true
+		}
+	}
+
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	return i > 0 && i+1 < len(text) &&
+		(text[i] == '.' || text[i] == ')') && text[i+1] == ' '
+}
+
+// endsSentence reports whether the text ends with sentence punctuation,
+// possibly followed by closing quotes or brackets.
+func endsSentence(text string) bool {
+	text = strings.TrimRight(text, "\"')]")
+	if text == "" {
+		return false
+	}
+
+	switch text[len(text)-1] {
+	case '.', '!', '?', ':':
+		return true
+	}
+	return false
+}
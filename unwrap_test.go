@@ -0,0 +1,64 @@
+package reflow
+
+import (
+	"testing"
+)
+
+func TestUnwrap(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+	}{
+		{"", ""},
+		{"foo", "foo"},
+		// Continuation lines are joined:
+		{
+			"the quick brown\nfox jumps over\nthe lazy dog",
+			"the quick brown fox jumps over the lazy dog",
+		},
+		// Trailing and leading spaces are collapsed:
+		{
+			"the quick brown \n  fox",
+			"the quick brown fox",
+		},
+		// Blank lines separate paragraphs:
+		{
+			"first\nparagraph\n\nsecond\nparagraph\n",
+			"first paragraph\n\nsecond paragraph\n",
+		},
+		// Lines ending a sentence aren't joined:
+		{
+			"Hello there.\nHow are\nyou?\nFine",
+			"Hello there.\nHow are you?\nFine",
+		},
+		{
+			"He said \"stop.\"\nThen",
+			"He said \"stop.\"\nThen",
+		},
+		// List items:
+		{
+			"Todo\n- buy\n  milk\n- call\n  mom\n* read\n+ sleep",
+			"Todo\n- buy milk\n- call mom\n* read\n+ sleep",
+		},
+		{
+			"1. first\nitem\n2) second\n10. tenth\n3 apples",
+			"1. first item\n2) second\n10. tenth 3 apples",
+		},
+		// Quoted email text:
+		{
+			"> the quick brown\n> fox jumps over\n>\n> the lazy dog\nreply\ntext",
+			"> the quick brown fox jumps over\n>\n> the lazy dog\nreply text",
+		},
+		{
+			"> outer\n> > inner\n> > quote\n>> more\n> outer",
+			"> outer\n> > inner quote more\n> outer",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := Unwrap(tc.Input)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package wordwrap
+
+// isBidiControl reports whether c is one of the invisible characters
+// controlling the direction of bidirectional text, such as the right-to-left
+// mark. They take up no room and are never word content on their own.
+func isBidiControl(c rune) bool {
+	switch {
+	case c == '\u061C': // arabic letter mark
+		return true
+	case c == '\u200E' || c == '\u200F': // left-to-right and right-to-left marks
+		return true
+	case c >= '\u202A' && c <= '\u202E': // embeddings and overrides
+		return true
+	case c >= '\u2066' && c <= '\u2069': // isolates
+		return true
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package wordwrap
+
+// noLineStart contains the characters which must not begin a line, following
+// the JIS X 4051 line-breaking (kinsoku) rules.
+var noLineStart = []rune(")]}）］｝〕〉》」』】〙〗〟’”｠»" +
+	"ヽヾーァィゥェォッャュョヮヵヶぁぃぅぇぉっゃゅょゎゕゖㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ々〻" +
+	"‐゠–〜～" +
+	"?!‼⁇⁈⁉？！" +
+	"・:;：；/／" +
+	"、,，。.．")
+
+// noLineEnd contains the characters which must not end a line, following the
+// JIS X 4051 line-breaking (kinsoku) rules.
+var noLineEnd = []rune("([{（［｛〔〈《「『【〘〖〝‘“｟«")
+
+// isWide reports whether c occupies two cells on the terminal, regardless of
+// the WidthFunc.
+func (w *WordWrap) isWide(c rune) bool {
+	return w.condition().RuneWidth(c) == 2
+}
+
+// breaksBefore reports whether the line may be broken between the pending
+// word and c.
+func (w *WordWrap) breaksBefore(c rune) bool {
+	if !w.isWide(c) || inGroup(noLineStart, c) {
+		return false
+	}
+	return w.word.Len() == 0 || !(inGroup(noLineEnd, w.lastRune) || w.lastRune == nbsp)
+}
@@ -0,0 +1,105 @@
+package wordwrap
+
+import (
+	"testing"
+)
+
+func TestWordWrapCJKRules(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		HardWrap bool
+	}{
+		// Wide characters are broken anywhere:
+		{
+			"日本語の文章",
+			"日本語\nの文章",
+			6,
+			false,
+		},
+		// Closing punctuation never starts a line:
+		{
+			"日本語。文章",
+			"日本\n語。文\n章",
+			6,
+			false,
+		},
+		{
+			"日本語、文章",
+			"日本\n語、文\n章",
+			6,
+			false,
+		},
+		{
+			"日本」語",
+			"日\n本」\n語",
+			4,
+			false,
+		},
+		// Small kana never start a line:
+		{
+			"きょうは",
+			"きょ\nうは",
+			4,
+			false,
+		},
+		// Opening brackets never end a line:
+		{
+			"日本「語」",
+			"日本\n「語」",
+			6,
+			false,
+		},
+		{
+			"日本（語）",
+			"日本\n（語）",
+			6,
+			false,
+		},
+		// No-break spaces glue wide characters:
+		{
+			"日本語 100\u00A0円",
+			"日本語\n100\u00A0円",
+			8,
+			false,
+		},
+		// Rules are kept when hard wrapping:
+		{
+			"日本語。",
+			"日本\n語。",
+			6,
+			true,
+		},
+		{
+			"abcdefg日本語",
+			"abcd\nefg\n日本\n語",
+			4,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.CJKRules = true
+		f.HardWrap = tc.HardWrap
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapCJKRulesDisabled(t *testing.T) {
+	actual := String("日本語の文章", 6)
+	expected := "日本語の文章"
+	if actual != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, actual)
+	}
+}
@@ -0,0 +1,63 @@
+package wordwrap
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultTabWidth is the tab width used by WrapCode if no valid one is given.
+const defaultTabWidth = 8
+
+// WrapCode word-wraps lines of source code at the given limit. Lines only get
+// broken at whitespace, so identifiers and other tokens are never split. The
+// leading indentation of each line is kept and repeated on its continuation
+// lines, with tabs counting up to the next multiple of tabWidth columns.
+func WrapCode(s string, limit, tabWidth int) string {
+	if tabWidth <= 0 {
+		tabWidth = defaultTabWidth
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		code := strings.TrimLeft(l, " \t")
+		if code == "" {
+			// blank line
+			continue
+		}
+		indent := l[:len(l)-len(code)]
+
+		col := indentWidth(indent, tabWidth)
+		width := limit - col
+		if width < 1 {
+			width = 1
+		}
+
+		f := NewWriterPipe(width,
+			WithTabWidth(tabWidth),
+			WithWordBoundaryFunc(func(_, cur rune) bool {
+				return unicode.IsSpace(cur)
+			}),
+		)
+		// the tab stops are those of the whole line
+		f.tabOffset = col
+		_, _ = f.Write([]byte(code))
+		_ = f.Close()
+
+		lines[i] = indent + strings.Join(f.Lines(), "\n"+indent)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// indentWidth returns the amount of columns the given indentation takes up.
+func indentWidth(indent string, tabWidth int) int {
+	var col int
+	for _, c := range indent {
+		if c == '\t' {
+			col = (col/tabWidth + 1) * tabWidth
+		} else {
+			col++
+		}
+	}
+	return col
+}
@@ -0,0 +1,69 @@
+package wordwrap
+
+import (
+	"testing"
+)
+
+func TestWrapCode(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		TabWidth int
+	}{
+		// Short lines are kept:
+		{
+			"func main() {\n\tfmt.Println(\"hi\")\n}",
+			"func main() {\n\tfmt.Println(\"hi\")\n}",
+			40,
+			4,
+		},
+		// Tokens are never split:
+		{
+			"return foo-bar+baz.qux(x)",
+			"return\nfoo-bar+baz.qux(x)",
+			12,
+			4,
+		},
+		// Indentation counts at the tab width and is repeated:
+		{
+			"\t\tif err := doSomething(ctx, arg); err != nil {",
+			"\t\tif err :=\n\t\tdoSomething(ctx,\n\t\targ); err != nil\n\t\t{",
+			24,
+			4,
+		},
+		{
+			"\t\tif err := doSomething(ctx, arg); err != nil {",
+			"\t\tif err := doSomething(ctx,\n\t\targ); err != nil {",
+			32,
+			2,
+		},
+		{
+			"  \tx = a + b",
+			"  \tx = a\n  \t+ b",
+			14,
+			8,
+		},
+		// Inner tabs advance to the tab stops of the whole line:
+		{
+			"  x\ty",
+			"  x y",
+			20,
+			4,
+		},
+		// Blank lines are left alone:
+		{
+			"a b c\n\t\n\ta b c",
+			"a b\nc\n\t\n\ta\n\tb\n\tc",
+			3,
+			2,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := WrapCode(tc.Input, tc.Limit, tc.TabWidth)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
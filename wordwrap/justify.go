@@ -0,0 +1,68 @@
+package wordwrap
+
+import (
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// justifyLine stretches the spaces between the words of the current line, so
+// it reaches the limit.
+func (w *WordWrap) justifyLine() {
+	extra := w.limit() - w.lineLen
+	if extra <= 0 {
+		return
+	}
+
+	line := justify(string(w.buf.Bytes()[w.lineStart:]), extra)
+	w.buf.Truncate(w.lineStart)
+	_, _ = w.buf.WriteString(line)
+	w.lineLen = w.stringWidth(line)
+	w.updateMaxWidth()
+}
+
+// justify distributes extra spaces evenly among the gaps between the words of
+// line. Gaps to the left get the remaining spaces, if they can't be
+// distributed evenly. Lines without gaps are returned as they are.
+func justify(line string, extra int) string {
+	var gaps []int // the offsets behind each gap
+	var word bool
+	var pos int
+	pending := -1
+
+	ansi.Parse([]byte(line), func(seq, text []byte) {
+		for i, c := range string(text) {
+			switch {
+			case c == ' ':
+				if word {
+					pending = pos + i + 1
+				}
+			case pending >= 0:
+				gaps = append(gaps, pending)
+				pending = -1
+				word = true
+			default:
+				word = true
+			}
+		}
+		pos += len(seq) + len(text)
+	})
+	if len(gaps) == 0 {
+		return line
+	}
+
+	var b strings.Builder
+	var last int
+	for i, g := range gaps {
+		n := extra / len(gaps)
+		if i < extra%len(gaps) {
+			n++
+		}
+		_, _ = b.WriteString(line[last:g])
+		_, _ = b.WriteString(strings.Repeat(" ", n))
+		last = g
+	}
+	_, _ = b.WriteString(line[last:])
+
+	return b.String()
+}
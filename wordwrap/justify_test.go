@@ -0,0 +1,90 @@
+package wordwrap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+func TestWordWrapJustify(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Spaces are distributed evenly, left gaps first:
+		{
+			"the quick brown fox jumps over the lazy dog",
+			"the  quick\nbrown  fox\njumps over\nthe   lazy\ndog",
+			10,
+		},
+		{
+			"a b c d e f g",
+			"a  b c d\ne f g",
+			8,
+		},
+		{
+			"aa b c ddddddd",
+			"aa   b  c\nddddddd",
+			9,
+		},
+		// The last line of each paragraph is kept:
+		{
+			"foo bar baz\nqux quux\n\nfoo",
+			"foo    bar\nbaz\nqux quux\n\nfoo",
+			10,
+		},
+		// Single words are kept:
+		{
+			"foobar foobarbaz baz",
+			"foobar\nfoobarbaz\nbaz",
+			10,
+		},
+		// Double-width characters:
+		{
+			"你好 世界 foo bar",
+			"你好   世界\nfoo bar",
+			11,
+		},
+		// ANSI sequences:
+		{
+			"\x1B[31mfoo\x1B[0m bar \x1B[1mbaz\x1B[0m qux",
+			"\x1B[31mfoo\x1B[0m    bar\x1B[0m\n\x1B[1m\x1B[1mbaz\x1B[0m qux",
+			10,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Justify = true
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapJustifyWidth(t *testing.T) {
+	f := NewWriter(24)
+	f.Justify = true
+
+	_, _ = f.Write([]byte("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."))
+	f.Close()
+
+	lines := strings.Split(f.String(), "\n")
+	for i, l := range lines[:len(lines)-1] {
+		if w := ansi.PrintableRuneWidth(l); w != 24 {
+			t.Errorf("line %d %q has width %d, expected 24", i, l, w)
+		}
+	}
+	if f.MaxWidth() != 24 {
+		t.Errorf("expected a max width of 24, got %d", f.MaxWidth())
+	}
+}
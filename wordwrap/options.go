@@ -0,0 +1,256 @@
+package wordwrap
+
+// Option configures a WordWrap instance.
+type Option func(*WordWrap)
+
+// NewWriterPipe returns a new instance of a word-wrapping writer, initialized
+// with default settings and then configured by the given options.
+func NewWriterPipe(limit int, opts ...Option) *WordWrap {
+	w := NewWriter(limit)
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithBreakpoints sets the runes after which a line may be broken.
+func WithBreakpoints(breakpoints []rune) Option {
+	return func(w *WordWrap) {
+		w.Breakpoints = breakpoints
+	}
+}
+
+// WithBreakpointFunc sets a function deciding whether a line may be broken
+// after a rune. It takes precedence over the breakpoints.
+func WithBreakpointFunc(f func(rune) bool) Option {
+	return func(w *WordWrap) {
+		w.BreakpointFunc = f
+	}
+}
+
+// WithBreakBefore sets the runes before which a line may be broken.
+func WithBreakBefore(breakBefore []rune) Option {
+	return func(w *WordWrap) {
+		w.BreakBefore = breakBefore
+	}
+}
+
+// WithWordBoundaryFunc sets a function deciding whether there's a word
+// boundary between two runes. It takes precedence over spaces and breakpoints.
+func WithWordBoundaryFunc(f func(prev, cur rune) bool) Option {
+	return func(w *WordWrap) {
+		w.WordBoundaryFunc = f
+	}
+}
+
+// WithNewline sets the runes which are treated as explicit line breaks.
+func WithNewline(newline []rune) Option {
+	return func(w *WordWrap) {
+		w.Newline = newline
+	}
+}
+
+// WithKeepNewlines sets whether explicit line breaks are kept.
+func WithKeepNewlines(keep bool) Option {
+	return func(w *WordWrap) {
+		w.KeepNewlines = keep
+	}
+}
+
+// WithNormalizeNewlines sets whether "\r\n" and lone "\r" are treated as line
+// breaks.
+func WithNormalizeNewlines(normalizeNewlines bool) Option {
+	return func(w *WordWrap) {
+		w.NormalizeNewlines = normalizeNewlines
+	}
+}
+
+// WithFormFeedBreaks sets the amount of line breaks replacing form feeds. If
+// it's 0, they get dropped, unless control characters get replaced.
+func WithFormFeedBreaks(n int) Option {
+	return func(w *WordWrap) {
+		w.FormFeedBreaks = n
+	}
+}
+
+// WithParagraphMode sets whether lines get joined, keeping only the blank
+// lines separating paragraphs.
+func WithParagraphMode(paragraphMode bool) Option {
+	return func(w *WordWrap) {
+		w.ParagraphMode = paragraphMode
+	}
+}
+
+// WithHardWrap sets whether words exceeding the limit get broken.
+func WithHardWrap(hardWrap bool) Option {
+	return func(w *WordWrap) {
+		w.HardWrap = hardWrap
+	}
+}
+
+// WithBreakLongWords sets whether words wider than the limit get broken.
+func WithBreakLongWords(breakLongWords bool) Option {
+	return func(w *WordWrap) {
+		w.BreakLongWords = breakLongWords
+	}
+}
+
+// WithOverflow sets how words wider than the limit are handled.
+func WithOverflow(mode OverflowMode) Option {
+	return func(w *WordWrap) {
+		w.Overflow = mode
+	}
+}
+
+// WithTabReplace sets the string tabs get replaced with when hard wrapping.
+func WithTabReplace(tabReplace string) Option {
+	return func(w *WordWrap) {
+		w.TabReplace = tabReplace
+	}
+}
+
+// WithTabWidth sets the distance between tab stops tabs get expanded to.
+func WithTabWidth(tabWidth int) Option {
+	return func(w *WordWrap) {
+		w.TabWidth = tabWidth
+	}
+}
+
+// WithPreserveSpaces sets whether spaces at line breaks are preserved.
+func WithPreserveSpaces(preserve bool) Option {
+	return func(w *WordWrap) {
+		w.PreserveSpaces = preserve
+	}
+}
+
+// WithCollapseSpaces sets whether whitespace between words gets squeezed into
+// a single space.
+func WithCollapseSpaces(collapseSpaces bool) Option {
+	return func(w *WordWrap) {
+		w.CollapseSpaces = collapseSpaces
+	}
+}
+
+// WithTrimIndent sets whether whitespace at the start of lines gets dropped.
+func WithTrimIndent(trimIndent bool) Option {
+	return func(w *WordWrap) {
+		w.TrimIndent = trimIndent
+	}
+}
+
+// WithGraphemeAware sets whether grapheme clusters are wrapped as a whole.
+func WithGraphemeAware(graphemeAware bool) Option {
+	return func(w *WordWrap) {
+		w.GraphemeAware = graphemeAware
+	}
+}
+
+// WithLineBreakSuffix sets the string appended to lines broken by the
+// wordwrapper.
+func WithLineBreakSuffix(suffix string) Option {
+	return func(w *WordWrap) {
+		w.LineBreakSuffix = suffix
+	}
+}
+
+// WithNoAnsiReset sets whether styles are kept open across line breaks,
+// instead of being reset before and restored after each of them.
+func WithNoAnsiReset(noAnsiReset bool) Option {
+	return func(w *WordWrap) {
+		w.NoAnsiReset = noAnsiReset
+	}
+}
+
+// WithJustify sets whether wrapped lines get stretched to reach the limit.
+func WithJustify(justify bool) Option {
+	return func(w *WordWrap) {
+		w.Justify = justify
+	}
+}
+
+// WithTrailingNewline sets whether non-empty output always ends with a line
+// break.
+func WithTrailingNewline(trailingNewline bool) Option {
+	return func(w *WordWrap) {
+		w.TrailingNewline = trailingNewline
+	}
+}
+
+// WithAutoHangingIndent sets whether lines broken by the wordwrapper are
+// indented like the input line they continue.
+func WithAutoHangingIndent(auto bool) Option {
+	return func(w *WordWrap) {
+		w.AutoHangingIndent = auto
+	}
+}
+
+// WithLinePrefix sets the prefix of every line, e.g. "> " to quote the text.
+// Input lines already starting with it don't get it twice.
+func WithLinePrefix(prefix string) Option {
+	return func(w *WordWrap) {
+		w.LinePrefix = prefix
+	}
+}
+
+// WithHangingIndent sets the indent of lines broken by the wordwrapper.
+func WithHangingIndent(hangingIndent string) Option {
+	return func(w *WordWrap) {
+		w.HangingIndent = hangingIndent
+	}
+}
+
+// WithFillBackground sets whether lines get padded up to the limit while a
+// background color is active.
+func WithFillBackground(fillBackground bool) Option {
+	return func(w *WordWrap) {
+		w.FillBackground = fillBackground
+	}
+}
+
+// WithReplaceControl sets whether control characters, other than newlines and
+// tabs, get replaced by the given string. An empty replacement drops them.
+func WithReplaceControl(replaceControl bool, replacement string) Option {
+	return func(w *WordWrap) {
+		w.ReplaceControl = replaceControl
+		w.ControlReplace = replacement
+	}
+}
+
+// WithWidthFunc sets the function measuring the width of runes.
+func WithWidthFunc(widthFunc func(rune) int) Option {
+	return func(w *WordWrap) {
+		w.WidthFunc = widthFunc
+	}
+}
+
+// WithSequenceMatchers sets the matchers measuring string sequences, such as
+// inline images.
+func WithSequenceMatchers(matchers ...SequenceMatcher) Option {
+	return func(w *WordWrap) {
+		w.SequenceMatchers = matchers
+	}
+}
+
+// WithLineFunc sets a function called with every completed line and its
+// printable width.
+func WithLineFunc(f func(line []byte, width int)) Option {
+	return func(w *WordWrap) {
+		w.LineFunc = f
+	}
+}
+
+// WithConsumingBreakpoints sets the runes after which a line may be broken,
+// which are dropped if it is.
+func WithConsumingBreakpoints(breakpoints []rune) Option {
+	return func(w *WordWrap) {
+		w.ConsumingBreakpoints = breakpoints
+	}
+}
+
+// WithNormalizeAnsi sets whether leading zeros get stripped from the arguments
+// of SGR sequences.
+func WithNormalizeAnsi(normalize bool) Option {
+	return func(w *WordWrap) {
+		w.NormalizeAnsi = normalize
+	}
+}
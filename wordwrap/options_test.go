@@ -0,0 +1,33 @@
+package wordwrap
+
+import (
+	"testing"
+)
+
+func TestNewWriterPipe(t *testing.T) {
+	f := NewWriterPipe(4,
+		WithBreakpoints([]rune{':'}),
+		WithNewline([]rune{'\r'}),
+		WithHardWrap(true),
+		WithTabReplace(" "),
+		WithPreserveSpaces(true),
+	)
+
+	_, err := f.Write([]byte("foo:bar\rfoo\tbar"))
+	if err != nil {
+		t.Error(err)
+	}
+	f.Close()
+
+	expected := "foo:\nbar\nfoo \nbar"
+	if f.String() != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, f.String())
+	}
+}
+
+func TestNewWriterPipeDefaults(t *testing.T) {
+	f := NewWriterPipe(4)
+	if !f.KeepNewlines || f.HardWrap || len(f.Breakpoints) != 1 || f.Breakpoints[0] != '-' {
+		t.Errorf("expected default settings, got %+v", f)
+	}
+}
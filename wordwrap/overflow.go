@@ -0,0 +1,124 @@
+package wordwrap
+
+import (
+	"github.com/muesli/reflow/ansi"
+)
+
+// OverflowMode describes how words wider than the limit are handled.
+type OverflowMode int
+
+const (
+	// OverflowLeave puts words wider than the limit on their own line,
+	// exceeding the limit.
+	OverflowLeave OverflowMode = iota
+	// OverflowBreak fills lines up to the limit with words wider than it,
+	// breaking them wherever the limit is reached.
+	OverflowBreak
+	// OverflowBreakAtBreakpoints breaks words wider than the limit at their
+	// breakpoints. Parts between them, which are still wider than the limit,
+	// get broken at the limit. It's the same as setting BreakLongWords.
+	OverflowBreakAtBreakpoints
+)
+
+// breakWord fills the current line with the leading part of the pending word,
+// which is wider than the limit, and continues the remainder on the next line.
+// The remainder is added right away, as is the rest of the word when it comes
+// in, so even huge words never get held completely.
+func (w *WordWrap) breakWord() {
+	defer func() {
+		w.overflow = true
+		w.flushWord()
+	}()
+
+	for w.wordWidth() > w.limit() {
+		if n := w.limit() - w.lineLen - w.space.Len(); n > 0 {
+			head := w.cutWord(n)
+			if len(head) == 0 && w.lineLen+w.space.Len() <= w.indentLen {
+				// not even a single character fits the line
+				return
+			}
+			w.addSpace()
+			w.lineLen += w.stringWidth(string(head)) + w.sequenceWidth(head)
+			_, _ = w.buf.Write(head)
+			w.updateMaxWidth()
+		}
+		w.addNewLine(true)
+	}
+}
+
+// flushWord adds the pending part of a broken word to the current line, or to
+// the next one if it's full.
+func (w *WordWrap) flushWord() {
+	width := w.wordWidth()
+	if w.lineLen+width > w.limit() && w.lineLen > w.indentLen {
+		w.addNewLine(true)
+	}
+	if w.word.Len() > 0 {
+		w.restartAnsi()
+	}
+	w.lineLen += width
+	_, _ = w.word.WriteTo(&w.buf)
+	w.updateMaxWidth()
+}
+
+// cutWord removes the leading part, which fits into the given width, from the
+// pending word and returns it. Escape sequences are kept with the characters
+// following them.
+func (w *WordWrap) cutWord(width int) []byte {
+	b := w.word.Bytes()
+	cut := -1
+	var n, pos int
+
+	ansi.Parse(b, func(seq, text []byte) {
+		if cut >= 0 {
+			return
+		}
+		if seq != nil {
+			if rw := w.sequenceWidth(seq); rw > 0 {
+				if n+rw > width {
+					cut = pos
+					return
+				}
+				n += rw
+			}
+			pos += len(seq)
+			return
+		}
+
+		fit := func(i, rw int) bool {
+			if n+rw > width {
+				cut = pos + i
+				return false
+			}
+			n += rw
+			return true
+		}
+		if w.GraphemeAware {
+			var i int
+			ansi.Graphemes(string(text), w.runeWidth, func(cluster string, _ []rune, rw int) {
+				if cut < 0 && fit(i, rw) {
+					i += len(cluster)
+				}
+			})
+		} else {
+			for i, c := range string(text) {
+				if !fit(i, w.runeWidth(c)) {
+					break
+				}
+			}
+		}
+		pos += len(text)
+	})
+	if cut < 0 {
+		cut = len(b)
+	}
+
+	head := make([]byte, cut)
+	copy(head, b[:cut])
+	tail := make([]byte, len(b)-cut)
+	copy(tail, b[cut:])
+
+	w.word.Reset()
+	_, _ = w.word.Write(tail)
+	return head
+}
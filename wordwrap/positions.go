@@ -0,0 +1,217 @@
+package wordwrap
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// BreakPositions returns the rune indices into s at which String(s, limit)
+// breaks lines. Each index refers to the first rune continuing on the next
+// line, while the line breaks present in s aren't reported. Escape sequences
+// count as runes of s, but never take up any width.
+func BreakPositions(s string, limit int) []int {
+	type printable struct {
+		c   rune
+		pos int
+	}
+
+	// the printable runes of the input, along with their rune indices
+	var in []printable
+	var pos int
+	ansi.Parse([]byte(s), func(seq, text []byte) {
+		pos += utf8.RuneCount(seq)
+		for _, c := range string(text) {
+			in = append(in, printable{c, pos})
+			pos++
+		}
+	})
+	out := []rune(ansi.Strip(String(s, limit)))
+
+	// match the wrapped result against the input, which only differs by
+	// inserted line breaks and dropped spaces, soft hyphens and zero-width
+	// spaces
+	var positions []int
+	var i int
+	for j := 0; j < len(out) && i < len(in); {
+		c := in[i].c
+		switch {
+		case out[j] == c:
+			i++
+			j++
+		case out[j] == '\n':
+			if !unicode.IsSpace(c) && c != shy && c != zwsp {
+				positions = append(positions, in[i].pos)
+				j++
+				continue
+			}
+			i++
+		case c == shy && out[j] == '-':
+			// the line got broken at the soft hyphen
+			i++
+			j++
+		default:
+			// dropped
+			i++
+		}
+	}
+
+	return positions
+}
+
+// Insertion describes a change the wordwrapper made to its input: the Removed
+// bytes at Offset, such as spaces at a line break, got replaced by Text, such
+// as the line break itself and the escape sequences ending and restarting the
+// styling around it.
+type Insertion struct {
+	Offset  int    // the byte offset into the input
+	Removed int    // the amount of bytes of the input removed at Offset
+	Text    string // the bytes inserted at Offset
+}
+
+// StringWithInsertions word-wraps s like String, additionally returning the
+// changes made to s in order. Applying them to s results in the wrapped
+// string, while MapOffset translates offsets into s, e.g. of annotations, to
+// offsets into the wrapped string.
+func StringWithInsertions(s string, limit int) (string, []Insertion) {
+	wrapped := String(s, limit)
+	in := tokens(s)
+	out := tokens(wrapped)
+
+	// the wrapped string only differs from the input by inserted line breaks
+	// and escape sequences, dropped spaces, soft hyphens and zero-width
+	// spaces and normalized escape sequences
+	var insertions []Insertion
+	var i, j int
+	for i < len(in) || j < len(out) {
+		if i < len(in) && j < len(out) && in[i].text == out[j].text {
+			i++
+			j++
+			continue
+		}
+
+		start, from := i, j
+		if j >= len(out) {
+			// dropped at the end
+			i = len(in)
+		}
+		for i < len(in) && (in[i].text == string(shy) || in[i].text == string(zwsp)) {
+			// unused soft hyphen or zero-width space
+			i++
+		}
+		if out.breaksAt(j) {
+			for i < len(in) && in[i].droppable() {
+				i++
+			}
+		}
+		for j < len(out) && (i >= len(in) || in[i].text != out[j].text) {
+			if i < len(in) && in[i].seq && out[j].seq {
+				// normalized escape sequence
+				i++
+			}
+			j++
+		}
+
+		ins := Insertion{
+			Offset: len(s),
+			Text:   wrapped[out.offset(from, len(wrapped)):out.offset(j, len(wrapped))],
+		}
+		ins.Removed = in.offset(i, len(s)) - in.offset(start, len(s))
+		if start < len(in) {
+			ins.Offset = in[start].offset
+		}
+		insertions = append(insertions, ins)
+	}
+
+	return wrapped, insertions
+}
+
+// StringWithCursor word-wraps s like String, additionally returning the
+// zero-based row and column the cursor of a terminal ends up at after printing
+// the wrapped string, e.g. to continue a prompt. A trailing line break moves it
+// to the start of the next row.
+func StringWithCursor(s string, limit int) (string, int, int) {
+	f := NewWriter(limit)
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	b := f.Bytes()
+	last := b[bytes.LastIndexByte(b, '\n')+1:]
+	col := f.stringWidth(string(last)) + f.sequenceWidth(last)
+	return string(b), bytes.Count(b, []byte("\n")), col
+}
+
+// MapOffset translates a byte offset into the input of StringWithInsertions
+// to the offset of the same byte within the wrapped string. Offsets of removed
+// bytes are mapped to the start of their replacement.
+func MapOffset(insertions []Insertion, offset int) int {
+	var shift int
+	for _, ins := range insertions {
+		if offset < ins.Offset {
+			break
+		}
+		if offset < ins.Offset+ins.Removed {
+			// removed
+			return ins.Offset + shift
+		}
+		shift += len(ins.Text) - ins.Removed
+	}
+
+	return offset + shift
+}
+
+// token is an escape sequence or a printable rune of a string.
+type token struct {
+	text   string
+	offset int
+	seq    bool
+}
+
+// droppable reports whether the wordwrapper may drop the token at a line
+// break.
+func (t token) droppable() bool {
+	return t.text == string(shy) || t.text == string(zwsp) || !t.seq && t.text != "\n" && unicode.IsSpace([]rune(t.text)[0])
+}
+
+type tokenList []token
+
+// tokens splits s into escape sequences and printable runes.
+func tokens(s string) tokenList {
+	var t tokenList
+	var offset int
+	ansi.Parse([]byte(s), func(seq, text []byte) {
+		if seq != nil {
+			t = append(t, token{string(seq), offset, true})
+			offset += len(seq)
+			return
+		}
+		for _, c := range string(text) {
+			t = append(t, token{string(c), offset, false})
+			offset += utf8.RuneLen(c)
+		}
+	})
+
+	return t
+}
+
+// offset returns the byte offset of the i-th token, or end past the last one.
+func (t tokenList) offset(i, end int) int {
+	if i < len(t) {
+		return t[i].offset
+	}
+	return end
+}
+
+// breaksAt reports whether the wordwrapper inserted a line break at the i-th
+// token, possibly preceded by a hyphen and a reset sequence.
+func (t tokenList) breaksAt(i int) bool {
+	if i < len(t) && t[i].text == "-" {
+		i++
+	}
+	if i < len(t) && t[i].text == "\x1B[0m" {
+		i++
+	}
+	return i < len(t) && t[i].text == "\n"
+}
@@ -0,0 +1,73 @@
+package wordwrap
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+const readChunkSize = 4096
+
+type reader struct {
+	r       io.Reader
+	w       *WordWrap
+	chunk   []byte
+	partial []byte // incomplete trailing rune of the last chunk
+	eof     bool
+}
+
+// NewReader returns a reader yielding the content of r word-wrapped at the
+// given limit. The content is wrapped incrementally: besides the chunk last
+// read from r, only the pending word and spaces, which may still move to the
+// next line, are held in memory.
+func NewReader(r io.Reader, limit int) io.Reader {
+	return &reader{
+		r: r,
+		w: NewWriter(limit),
+	}
+}
+
+// Read reads the next word-wrapped bytes into p.
+func (r *reader) Read(p []byte) (int, error) {
+	for r.w.buf.Len() == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.w.buf.Read(p)
+}
+
+// fill reads the next chunk from the underlying reader and feeds it to the
+// wordwrapper.
+func (r *reader) fill() error {
+	if r.chunk == nil {
+		r.chunk = make([]byte, readChunkSize)
+	}
+
+	n, err := r.r.Read(r.chunk)
+	b := append(r.partial, r.chunk[:n]...)
+	r.partial = nil
+
+	if err == io.EOF {
+		_, _ = r.w.Write(b)
+		r.eof = true
+		return r.w.Close()
+	}
+
+	// hold back a rune that got split across reads
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				r.partial = append(r.partial, b[i:]...)
+				b = b[:i]
+			}
+			break
+		}
+	}
+
+	_, _ = r.w.Write(b)
+	return err
+}
@@ -0,0 +1,74 @@
+package wordwrap
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	tt := []struct {
+		Input string
+		Limit int
+	}{
+		{
+			"foo bar foo\nbar foo-bar",
+			4,
+		},
+		// Multi-byte runes:
+		{
+			"你好 世界 你好",
+			5,
+		},
+		// ANSI sequences:
+		{
+			"\x1B[38;2;249;38;114m(\x1B[0m\x1B[38;2;248;248;242mjust another test\x1B[38;2;249;38;114m)\x1B[0m",
+			3,
+		},
+		// Limit of zero passes through:
+		{
+			"foo bar\n",
+			0,
+		},
+	}
+
+	for i, tc := range tt {
+		expected := String(tc.Input, tc.Limit)
+
+		// feed the reader a single byte at a time, splitting runes and ANSI
+		// sequences across reads
+		r := NewReader(iotest.OneByteReader(strings.NewReader(tc.Input)), tc.Limit)
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if string(b) != expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, expected, string(b))
+		}
+
+		r = NewReader(strings.NewReader(tc.Input), tc.Limit)
+		b, err = ioutil.ReadAll(iotest.OneByteReader(r))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if string(b) != expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, expected, string(b))
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(iotest.TimeoutReader(strings.NewReader("foo bar")), 4)
+
+	// the first read succeeds, the second one fails
+	p := make([]byte, 1)
+	if _, err := r.Read(p); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := ioutil.ReadAll(r); err != iotest.ErrTimeout {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package wordwrap
+
+import (
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// SequenceMatcher returns the amount of cells a string sequence, such as an
+// OSC or APC sequence, takes up on the terminal. It reports false for
+// sequences it doesn't recognize. The sequence is passed including its
+// introducer and terminator.
+type SequenceMatcher func(seq string) (width int, ok bool)
+
+// SequencePrefix returns a SequenceMatcher recognizing string sequences
+// starting with prefix, e.g. "\x1b_G" for images of the kitty graphics
+// protocol or "\x1b]1337;File=" for inline images of iTerm2.
+func SequencePrefix(prefix string, width int) SequenceMatcher {
+	return func(seq string) (int, bool) {
+		return width, strings.HasPrefix(seq, prefix)
+	}
+}
+
+// sequenceWidth returns the amount of cells taken up by the string sequences
+// within b, as recognized by the sequence matchers.
+func (w *WordWrap) sequenceWidth(b []byte) int {
+	if len(w.SequenceMatchers) == 0 {
+		return 0
+	}
+
+	var n int
+	ansi.Parse(b, func(seq, _ []byte) {
+		if len(seq) < 2 || !inGroup(stringSequences, rune(seq[1])) {
+			return
+		}
+		for _, m := range w.SequenceMatchers {
+			if width, ok := m(string(seq)); ok {
+				n += width
+				return
+			}
+		}
+	})
+	return n
+}
@@ -2,36 +2,127 @@ package wordwrap
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 
 	runewidth "github.com/mattn/go-runewidth"
 	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
 )
 
+const (
+	// nbsp is the no-break space, which glues the words around it together.
+	nbsp = '\u00A0'
+	// shy is the soft hyphen, marking where a word may be broken. It's only
+	// shown, as a hyphen, if the line gets broken there.
+	shy = '\u00AD'
+	// zwsp is the zero-width space, marking where a word may be broken. It's
+	// never shown.
+	zwsp = '\u200B'
+)
+
+// URLBreakpoints are the breakpoints for wrapping URLs and paths at their
+// segments, e.g. with WithBreakpoints. Lines are broken after them.
+var URLBreakpoints = []rune{'-', '/', '.'}
+
 var (
 	defaultBreakpoints = []rune{'-'}
 	defaultNewline     = []rune{'\n'}
+
+	// runes introducing string sequences after an escape, such as the
+	// operating system command (OSC) used for hyperlinks
+	stringSequences = []rune{']', 'P', 'X', '^', '_'}
 )
 
 // WordWrap contains settings and state for customisable text reflowing with
 // support for ANSI escape sequences. This means you can style your terminal
 // output without affecting the word wrapping algorithm.
 type WordWrap struct {
-	Limit          int
-	Breakpoints    []rune
-	Newline        []rune
-	KeepNewlines   bool
-	HardWrap       bool
-	TabReplace     string // since tabs can have different lengths, replace them with this when hardwrap is enabled
-	PreserveSpaces bool
+	Limit             int
+	Breakpoints       []rune
+	BreakpointFunc    func(rune) bool           // takes precedence over Breakpoints if set
+	BreakBefore       []rune                    // runes before which a line may be broken, e.g. opening brackets
+	WordBoundaryFunc  func(prev, cur rune) bool // reports word boundaries between two runes; takes precedence over spaces and breakpoints if set
+	Newline           []rune
+	KeepNewlines      bool
+	NormalizeNewlines bool   // treat "\r\n" and lone "\r" as line breaks
+	ReplaceControl    bool   // replace control characters, other than newlines and tabs, by ControlReplace
+	ControlReplace    string // the replacement of control characters, which are dropped if empty
+	ParagraphMode     bool   // join lines, but keep blank lines separating paragraphs; takes precedence over KeepNewlines
+	FormFeedBreaks    int    // the amount of line breaks replacing form feeds, e.g. 2 to separate pages by a blank line; form feeds are dropped if 0, or handled by ReplaceControl if set
+	HardWrap          bool
+	BreakLongWords    bool              // break words which are wider than the limit, but wrap all others as a whole
+	Overflow          OverflowMode      // how words wider than the limit are handled
+	SequenceMatchers  []SequenceMatcher // measure string sequences, such as inline images, which are zero-width otherwise
+	TabReplace        string            // since tabs can have different lengths, replace them with this when hardwrap is enabled
+	TabWidth          int               // expand tabs to the next multiple of TabWidth columns, takes precedence over TabReplace
+	PreserveSpaces    bool
+	CollapseSpaces    bool                 // squeeze whitespace between words into a single space
+	TrimIndent        bool                 // drop whitespace at the start of lines
+	GraphemeAware     bool                 // measure and wrap grapheme clusters instead of single runes
+	Condition         *runewidth.Condition // measures the width of runes, e.g. treating ambiguous ones as wide; runewidth.DefaultCondition if nil
+	WidthFunc         func(rune) int       // measures the width of runes instead of Condition, e.g. to count runes
+	CJKRules          bool                 // break between wide characters, following the kinsoku rules
+	LineBreakSuffix   string               // appended to lines broken by the wordwrapper, e.g. a continuation glyph
+	HangingIndent     string               // prepended to lines broken by the wordwrapper, e.g. to indent the continuation of list items
+	LinePrefix        string               // prepended to every line, e.g. "> " to quote the text; removed from the input lines starting with it first
+	AutoHangingIndent bool                 // indent lines broken by the wordwrapper like the input line they continue, followed by the HangingIndent
+	NoAnsiReset       bool                 // keep styles open across line breaks instead of resetting and restoring them
+	NormalizeAnsi     bool                 // strip leading zeros from the arguments of SGR sequences; they're passed through byte for byte otherwise
+	FillBackground    bool                 // pad lines up to the limit while a background color is active
+	Justify           bool                 // stretch the spaces of wrapped lines, except the last one of each paragraph, to reach the limit
+	TrailingNewline   bool                 // end non-empty output with a line break, unless it already does
+
+	// LineFunc is called with every completed line and its printable width,
+	// with the last one on Close, e.g. to stream lines to a renderer.
+	LineFunc func(line []byte, width int)
+
+	// ConsumingBreakpoints are runes after which a line may be broken, like
+	// Breakpoints, but which are dropped if it is, e.g. '|' separating the
+	// parts of templated content.
+	ConsumingBreakpoints []rune
 
 	buf   bytes.Buffer // processed and, in line, accepted bytes
 	space bytes.Buffer // pending continues spaces bytes
 	word  ansi.Buffer  // pending continues word bytes
+	seq   bytes.Buffer // pending escape sequence bytes
+
+	lineLen   int        // the visible length of the line not accurate for tabs
+	lineStart int        // the offset of the current line within buf
+	indentLen int        // the visible length of the current line's hanging indent
+	carried   ansi.Style // the style carried over to the current line, if not restarted
+	maxWidth  int        // the visible length of the widest line so far
+	newlines  int        // the amount of line breaks written so far
+	ansi      bool
+	osc       bool // within an operating system command or another string sequence
+	oscEsc    bool // the last rune of the string sequence was an escape
+	oscStart  int  // the offset of the string sequence within the word
+	lastRune  rune // the last printable rune written to the word
+	prevRune  rune // the last printable rune passed to the WordBoundaryFunc
+	lastCR    bool // the last write ended with a carriage return
+	hyphen    bool // the current line ends at a soft hyphen
+	overflow  bool // the pending word got broken, so its rest is added as it comes
+	overflown bool // a line exceeded the limit
+	discard   bool // drop completed lines, which are only measured
+	prefixed  bool // the current line got its line prefix
+	midLine   bool // the input written so far doesn't end with a line break
+
+	consumed      string // the consuming breakpoint last added to buf
+	consumedAt    int    // the offset of the consuming breakpoint within buf
+	consumedWidth int    // the width of the consuming breakpoint
+
+	spaced    int // the width of the spaces last added to buf
+	spacedEnd int // the length of buf right after them
+	tabOffset int // the column the lines start at, which tab stops count from
+
+	paraBreaks  int    // the line breaks since the last content, in ParagraphMode
+	paraSpace   []byte // the spaces since the last content on the current line
+	paraStarted bool   // any content got written in ParagraphMode
 
-	lineLen int // the visible length of the line not accurate for tabs
-	ansi    bool
+	autoIndent string // the indentation of the current input line
+	indented   bool   // the indentation of the current input line is complete
 
 	wroteBegin bool         // mark is since the last newline something has written to the buffer (for ansi restart)
 	lastAnsi   bytes.Buffer // hold last active ansi sequence
@@ -46,10 +137,11 @@ type WordWrap struct {
 // default settings.
 func NewWriter(limit int) *WordWrap {
 	return &WordWrap{
-		Limit:        limit,
-		Breakpoints:  defaultBreakpoints,
-		Newline:      defaultNewline,
-		KeepNewlines: true,
+		Limit:         limit,
+		Breakpoints:   defaultBreakpoints,
+		Newline:       defaultNewline,
+		KeepNewlines:  true,
+		NormalizeAnsi: true,
 	}
 }
 
@@ -69,6 +161,110 @@ func String(s string, limit int) string {
 	return string(Bytes([]byte(s), limit))
 }
 
+// pool holds default WordWrap instances for AppendWrapped.
+var pool = sync.Pool{
+	New: func() interface{} {
+		return NewWriter(0)
+	},
+}
+
+// AppendWrapped appends src, word-wrapped at the given limit with the default
+// settings, to dst and returns the extended slice. The wordwrappers are
+// pooled, so repeated calls avoid allocating.
+func AppendWrapped(dst []byte, src []byte, limit int) []byte {
+	f := pool.Get().(*WordWrap)
+	defer pool.Put(f)
+
+	f.Reset()
+	f.Limit = limit
+	_, _ = f.Write(src)
+	_ = f.Close()
+
+	return append(dst, f.Bytes()...)
+}
+
+// Lines is shorthand for declaring a new default WordWrap instance,
+// used to immediately word-wrap a string into lines.
+func Lines(s string, limit int) []string {
+	f := NewWriter(limit)
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.Lines()
+}
+
+// Height returns the amount of lines s occupies, when word-wrapped at the
+// given limit with the default settings. It matches the lines returned by
+// Lines, while the wrapped result itself is discarded. To account for an
+// indentation, subtract its width from the limit.
+func Height(s string, limit int) int {
+	f := pool.Get().(*WordWrap)
+	defer pool.Put(f)
+
+	f.Reset()
+	f.Limit = limit
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.LineCount()
+}
+
+// Measure returns the printable width of the widest line and the amount of
+// lines s occupies, when word-wrapped at the given limit with the given
+// options. Completed lines are measured and then discarded, so the wrapped
+// result is never held as a whole.
+func Measure(s string, limit int, opts ...Option) (width, height int) {
+	f := NewWriterPipe(limit, opts...)
+	f.discard = true
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.MaxWidth(), f.LineCount()
+}
+
+// Clamp word-wraps s like String, but keeps at most maxLines lines. If any
+// content got dropped, the last words of the last kept line are replaced by
+// the indicator, e.g. "…", so that both still fit the limit. An indicator
+// wider than the limit is cut.
+func Clamp(s string, limit, maxLines int, indicator string) string {
+	f := NewWriter(limit)
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	lines := f.Lines()
+	if len(lines) <= maxLines {
+		return f.String()
+	}
+	if maxLines <= 0 {
+		return ""
+	}
+
+	if limit > 0 && ansi.PrintableRuneWidth(indicator) > limit {
+		indicator = truncate.String(indicator, uint(limit))
+	}
+	n := limit - ansi.PrintableRuneWidth(indicator)
+	if n < 0 {
+		n = 0
+	}
+
+	lines = lines[:maxLines]
+	last := lines[len(lines)-1]
+	if limit > 0 && ansi.PrintableRuneWidth(last) > n {
+		t := truncate.NewWriter(uint(n), "")
+		t.WordBoundary = true
+		_, _ = t.Write([]byte(last))
+		_ = t.Close()
+		last = t.String()
+	}
+	last = strings.TrimRight(last, " ")
+
+	out := strings.Join(append(lines[:len(lines)-1], last+indicator), "\n")
+	if !ansi.ActiveStyle(out).IsZero() {
+		out += "\x1B[0m"
+	}
+	return out
+}
+
 // HardWrap is a shorthand for declaring a new hardwrapping WordWrap instance,
 // since variable length characters can not be hard wrapped to a fixed length,
 // tabs will be replaced by TabReplace, use according amount of spaces.
@@ -84,47 +280,345 @@ func HardWrap(s string, limit int, tabReplace string) string {
 
 // adds pending spaces to the buf(fer) and then resets the space buffer.
 func (w *WordWrap) addSpace() {
-	if w.space.Len() <= w.Limit-w.lineLen {
+	if w.space.Len() == 0 {
+		return
+	}
+	limit := w.limit()
+	if w.space.Len() <= limit-w.lineLen {
 		w.lineLen += w.space.Len()
 		_, _ = w.buf.Write(w.space.Bytes())
+		w.markSpaced(w.space.Len())
+	} else if !w.PreserveSpaces && w.lineLen > w.indentLen {
+		// the spaces only separate words, so they don't continue on the
+		// next line
+		w.addNewLine(true)
 	} else {
 		length := w.space.Len()
-		first := w.Limit - w.lineLen
+		first := limit - w.lineLen
+		if first < 0 {
+			// the line already exceeds the limit
+			first = 0
+		}
 		_, _ = w.buf.WriteString(strings.Repeat(" ", first))
+		w.lineLen += first
+		w.markSpaced(first)
 		length -= first
-		for length >= w.Limit {
-			_, _ = w.buf.WriteString("\n" + strings.Repeat(" ", w.Limit))
-			length -= w.Limit
-		}
-		if length > 0 {
-			_, _ = w.buf.WriteString("\n" + strings.Repeat(" ", length))
+		for length > 0 {
+			w.updateMaxWidth()
+			w.addSuffix()
+			w.emitLine()
+			_, _ = w.buf.WriteString("\n")
+			w.newlines++
+			w.lineLen = 0
+			w.lineStart = w.buf.Len()
+			w.addLinePrefix()
+			w.addHangingIndent()
+
+			n := length
+			if n > limit-w.lineLen {
+				n = limit - w.lineLen
+			}
+			if n < 1 {
+				n = 1
+			}
+			_, _ = w.buf.WriteString(strings.Repeat(" ", n))
+			w.lineLen += n
+			w.markSpaced(n)
+			length -= n
 		}
-		w.lineLen = length
 	}
 	w.space.Reset()
+	w.updateMaxWidth()
 }
 
 func (w *WordWrap) addWord() {
+	w.overflow = false
 	if w.word.Len() > 0 {
+		if w.Overflow == OverflowBreak &&
+			w.lineLen+w.space.Len()+w.wordWidth() > w.limit() &&
+			w.lineLen+w.space.Len() > w.indentLen {
+			// the word got kept on the line, in case it needed to be broken
+			w.addNewLine(true)
+		}
 		w.addSpace()
-		w.lineLen += w.word.PrintableRuneWidth()
+		w.hyphen = false
+		width := w.wordWidth()
+		spaced := w.trailingSpaces() > 0
+		w.lineLen += width
 		_, _ = w.buf.Write(w.word.Bytes())
 		w.word.Reset()
+		if spaced && width == 0 {
+			// only escape sequences follow the spaces
+			w.spacedEnd = w.buf.Len()
+		}
+		w.updateMaxWidth()
+	}
+}
+
+// markSpaced records that n cells of spaces just got added to the current
+// line.
+func (w *WordWrap) markSpaced(n int) {
+	if w.buf.Len()-n == w.spacedEnd {
+		// continuing the spaces before
+		w.spaced += n
+	} else {
+		w.spaced = n
+	}
+	w.spacedEnd = w.buf.Len()
+}
+
+// trailingSpaces returns the width of the spaces the current line ends with,
+// not counting towards its measured width.
+func (w *WordWrap) trailingSpaces() int {
+	if w.buf.Len() == w.spacedEnd {
+		return w.spaced
+	}
+	return 0
+}
+
+func (w *WordWrap) updateMaxWidth() {
+	width := w.lineLen - w.trailingSpaces()
+	if width > w.maxWidth {
+		w.maxWidth = width
+	}
+	if w.Limit > 0 && width > w.Limit {
+		w.overflown = true
+	}
+}
+
+// wordWidth returns the printable width of the pending word.
+func (w *WordWrap) wordWidth() int {
+	return w.printableWidth(w.word.Bytes())
+}
+
+// printableWidth returns the printable width of b, measured per grapheme
+// cluster if GraphemeAware is set.
+func (w *WordWrap) printableWidth(b []byte) int {
+	n := w.sequenceWidth(b)
+	if w.GraphemeAware {
+		return n + w.graphemeWidth(ansi.Strip(string(b)))
+	}
+	return n + w.stringWidth(string(b))
+}
+
+// condition returns the condition the widths of runes are measured by.
+func (w *WordWrap) condition() *runewidth.Condition {
+	if w.Condition != nil {
+		return w.Condition
+	}
+	return runewidth.DefaultCondition
+}
+
+// runeWidth returns the printable width of c.
+func (w *WordWrap) runeWidth(c rune) int {
+	if isBidiControl(c) {
+		return 0
+	}
+	if w.WidthFunc != nil {
+		return w.WidthFunc(c)
 	}
+	return w.condition().RuneWidth(c)
 }
 
-func (w *WordWrap) addNewLine() {
+// graphemeWidth returns the printable width of s, measured per grapheme
+// cluster.
+func (w *WordWrap) graphemeWidth(s string) int {
+	var n int
+	ansi.Graphemes(s, w.runeWidth, func(_ string, _ []rune, width int) {
+		n += width
+	})
+	return n
+}
+
+// stringWidth returns the printable width of s, ignoring escape sequences.
+func (w *WordWrap) stringWidth(s string) int {
+	return ansi.PrintableRuneWidthFunc(s, w.runeWidth)
+}
+
+// limit returns the limit words get wrapped at, leaving room for the line
+// break suffix.
+func (w *WordWrap) limit() int {
+	limit := w.Limit - w.stringWidth(w.LineBreakSuffix)
+	if limit < 1 {
+		return 1
+	}
+	return limit
+}
+
+// addSuffix adds the line break suffix to the current line.
+func (w *WordWrap) addSuffix() {
+	if w.LineBreakSuffix == "" {
+		return
+	}
+	_, _ = w.buf.WriteString(w.LineBreakSuffix)
+	w.lineLen += w.stringWidth(w.LineBreakSuffix)
+	w.updateMaxWidth()
+}
+
+// addNewLine adds a line break. soft marks breaks inserted by the wordwrapper,
+// as opposed to the ones present in the input.
+func (w *WordWrap) addNewLine(soft bool) {
 	if w.PreserveSpaces {
 		w.addSpace()
 	}
-	if w.lastAnsi.Len() != 0 {
+	if w.onlyLinePrefix() {
+		// blank lines don't end with the spaces of the prefix
+		w.buf.Truncate(w.buf.Len() - len(w.LinePrefix))
+		_, _ = w.buf.WriteString(strings.TrimRight(w.LinePrefix, " "))
+	}
+	if soft && w.consumed != "" && w.consumedAt+len(w.consumed) == w.buf.Len() {
+		// the line got broken at a consuming breakpoint
+		w.buf.Truncate(w.consumedAt)
+		w.lineLen -= w.consumedWidth
+	}
+	if soft {
+		if w.hyphen {
+			// the line got broken at a soft hyphen
+			_, _ = w.buf.WriteRune('-')
+			w.lineLen++
+			w.updateMaxWidth()
+		}
+		if w.Justify {
+			w.justifyLine()
+		}
+		w.addSuffix()
+	}
+	if w.FillBackground {
+		w.fillBackground()
+	}
+	if !w.NoAnsiReset && w.styled() {
 		// end ansi before linebreak
 		_, _ = w.buf.WriteString("\x1B[0m")
 	}
+	w.emitLine()
 	_, _ = w.buf.WriteRune('\n')
+	w.newlines++
 	w.lineLen = 0
+	w.lineStart = w.buf.Len()
+	w.indentLen = 0
+	w.addLinePrefix()
+	if soft {
+		w.addHangingIndent()
+	}
 	w.space.Reset()
+	w.hyphen = false
+	w.consumed = ""
+	w.spaced = 0
 	w.wroteBegin = false
+	if w.discard {
+		w.discardLines()
+	}
+}
+
+// emitLine passes the current line to the LineFunc, if any.
+func (w *WordWrap) emitLine() {
+	if w.LineFunc == nil {
+		return
+	}
+
+	b := w.buf.Bytes()
+	line := b[bytes.LastIndexByte(b, '\n')+1:]
+	w.LineFunc(line, w.printableWidth(line))
+}
+
+// discardLines drops the completed lines from the buffer.
+func (w *WordWrap) discardLines() {
+	i := bytes.LastIndexByte(w.buf.Bytes()[:w.lineStart], '\n')
+	if i < 0 {
+		return
+	}
+	w.buf.Next(i + 1)
+	w.lineStart -= i + 1
+}
+
+// fillBackground pads the current line up to the limit, if a background color
+// is active at its end.
+func (w *WordWrap) fillBackground() {
+	style := ansi.ActiveStyle(w.carried.Sequence() + string(w.buf.Bytes()[w.lineStart:]))
+	if w.NoAnsiReset {
+		// the style is still active on the next line
+		w.carried = style
+	}
+	if style.Background == "" || w.lineLen >= w.Limit {
+		return
+	}
+
+	_, _ = w.buf.WriteString(strings.Repeat(" ", w.Limit-w.lineLen))
+	w.lineLen = w.Limit
+	w.updateMaxWidth()
+}
+
+// addHangingIndent indents a line broken by the wordwrapper.
+func (w *WordWrap) addHangingIndent() {
+	w.indentLen = w.lineLen
+	indent := w.HangingIndent
+	if w.AutoHangingIndent {
+		indent = w.autoIndent + indent
+	}
+	if indent == "" {
+		return
+	}
+
+	_, _ = w.buf.WriteString(indent)
+	w.indentLen += w.stringWidth(indent)
+	w.lineLen = w.indentLen
+	w.lineStart = w.buf.Len()
+}
+
+// addLinePrefix starts a line with the line prefix.
+func (w *WordWrap) addLinePrefix() {
+	w.prefixed = true
+	if w.LinePrefix == "" {
+		return
+	}
+
+	_, _ = w.buf.WriteString(w.LinePrefix)
+	w.lineLen += w.stringWidth(w.LinePrefix)
+	w.indentLen = w.lineLen
+	w.lineStart = w.buf.Len()
+}
+
+// onlyLinePrefix reports whether the current line holds nothing but the line
+// prefix.
+func (w *WordWrap) onlyLinePrefix() bool {
+	return w.LinePrefix != "" && w.prefixed && w.buf.Len() == w.lineStart &&
+		w.lineLen == w.stringWidth(w.LinePrefix) &&
+		bytes.HasSuffix(w.buf.Bytes(), []byte(w.LinePrefix))
+}
+
+// trimLinePrefix drops the line prefix of a trailing empty line.
+func (w *WordWrap) trimLinePrefix() {
+	if !w.onlyLinePrefix() {
+		return
+	}
+
+	w.buf.Truncate(w.buf.Len() - len(w.LinePrefix))
+	w.lineStart = w.buf.Len()
+	w.lineLen = 0
+	w.indentLen = 0
+	w.prefixed = false
+}
+
+// stripLinePrefix removes the line prefix from the lines of s starting with
+// it, or consisting of it without its trailing spaces.
+func (w *WordWrap) stripLinePrefix(s string) string {
+	trimmed := strings.TrimRight(w.LinePrefix, " ")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if i == 0 && w.midLine {
+			continue
+		}
+		if strings.HasPrefix(l, w.LinePrefix) {
+			lines[i] = l[len(w.LinePrefix):]
+		} else if l == trimmed && i < len(lines)-1 {
+			lines[i] = ""
+		}
+	}
+	if s != "" {
+		w.midLine = !strings.HasSuffix(s, "\n")
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 func inGroup(a []rune, c rune) bool {
@@ -136,141 +630,683 @@ func inGroup(a []rune, c rune) bool {
 	return false
 }
 
+// joinParagraphs joins the lines of each paragraph of s with spaces. Runs of
+// blank lines are reduced to a single one separating the paragraphs, leading
+// and trailing ones are dropped. Lines and paragraphs may continue across
+// writes.
+func (w *WordWrap) joinParagraphs(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '\n':
+			// the spaces ending the line are dropped
+			w.paraBreaks++
+			w.paraSpace = w.paraSpace[:0]
+		case unicode.IsSpace(c):
+			w.paraSpace = append(w.paraSpace, string(c)...)
+		default:
+			if w.paraStarted {
+				switch {
+				case w.paraBreaks > 1:
+					_, _ = b.WriteString("\n\n")
+				case w.paraBreaks == 1:
+					_ = b.WriteByte(' ')
+				default:
+					_, _ = b.Write(w.paraSpace)
+				}
+			}
+			_, _ = b.WriteRune(c)
+			w.paraStarted = true
+			w.paraBreaks = 0
+			w.paraSpace = w.paraSpace[:0]
+		}
+	}
+
+	return b.String()
+}
+
+// tabSize returns the amount of spaces a tab at the current column expands to.
+// Tabs never advance beyond the end of the line.
+func (w *WordWrap) tabSize() int {
+	col := w.lineLen + w.space.Len()
+	n := w.TabWidth - (w.tabOffset+col)%w.TabWidth
+	if col+n > w.limit() {
+		n = w.limit() - col
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// isSeparator reports whether c is a control character or a space, which are
+// handled on their own even if followed by combining runes.
+func isSeparator(c rune) bool {
+	return unicode.IsControl(c) || unicode.IsSpace(c)
+}
+
+// isControl reports whether c is a C0 control character, other than a tab or
+// the escape introducing escape sequences.
+func isControl(c rune) bool {
+	return c < 0x20 && c != '\t' && c != '\x1B'
+}
+
+// isBreakpoint reports whether a line may be broken after c.
+func (w *WordWrap) isBreakpoint(c rune) bool {
+	if w.BreakpointFunc != nil {
+		return w.BreakpointFunc(c)
+	}
+	return inGroup(w.Breakpoints, c)
+}
+
+// normalizeNewlines replaces "\r\n" and lone "\r" in s with the first of the
+// configured newlines. A "\r\n" split between two writes is recognized, too.
+func (w *WordWrap) normalizeNewlines(s string) string {
+	nl := w.newline()
+
+	if w.lastCR && strings.HasPrefix(s, "\n") {
+		// the carriage return already ended the line
+		s = s[1:]
+	}
+	w.lastCR = strings.HasSuffix(s, "\r")
+
+	s = strings.Replace(s, "\r\n", nl, -1)
+	return strings.Replace(s, "\r", nl, -1)
+}
+
+// replaceFormFeeds replaces form feeds in s with the configured amount of
+// line breaks, unless they are line breaks themselves or get replaced like
+// other control characters.
+func (w *WordWrap) replaceFormFeeds(s string) string {
+	if inGroup(w.Newline, '\f') || (w.FormFeedBreaks == 0 && w.ReplaceControl) {
+		return s
+	}
+	return strings.Replace(s, "\f", strings.Repeat(w.newline(), w.FormFeedBreaks), -1)
+}
+
+// newline returns the first of the configured newlines.
+func (w *WordWrap) newline() string {
+	if len(w.Newline) > 0 {
+		return string(w.Newline[0])
+	}
+	return "\n"
+}
+
 // Write is used to write more content to the word-wrap buffer.
 func (w *WordWrap) Write(b []byte) (int, error) {
+	s := string(b)
+	if w.NormalizeNewlines {
+		s = w.normalizeNewlines(s)
+	}
+
 	if w.Limit == 0 {
-		return w.buf.Write(b)
+		for i, l := range strings.Split(s, "\n") {
+			if i > 0 {
+				w.emitLine()
+				_ = w.buf.WriteByte('\n')
+				w.newlines++
+				w.lineLen = 0
+			}
+			_, _ = w.buf.WriteString(l)
+			w.lineLen += w.stringWidth(l)
+			if n := len(l) - len(strings.TrimRight(l, " ")); n > 0 {
+				w.markSpaced(n)
+			}
+			w.updateMaxWidth()
+		}
+		return len(b), nil
 	}
 
-	s := string(b)
-	if !w.KeepNewlines {
-		s = strings.Replace(strings.TrimSpace(s), "\n", " ", -1)
+	s = w.replaceFormFeeds(s)
+	if w.LinePrefix != "" {
+		s = w.stripLinePrefix(s)
+	}
+	if w.ParagraphMode {
+		s = w.joinParagraphs(s)
+	} else if !w.KeepNewlines {
+		if w.PreserveSpaces {
+			// only drop surrounding line breaks, but keep the spaces
+			s = strings.Trim(s, "\n")
+		} else {
+			s = strings.TrimSpace(s)
+		}
+		s = strings.Replace(s, "\n", " ", -1)
 	}
 
-	if w.HardWrap {
+	if w.HardWrap && w.TabWidth <= 0 {
 		s = strings.Replace(s, "\t", w.TabReplace, -1)
 	}
 
-	for _, c := range s {
-		// Restart Ansi after line break if there is more text
-		if !w.wroteBegin && !w.ansi && w.lastAnsi.Len() != 0 {
-			_, _ = w.buf.Write(w.lastAnsi.Bytes())
-			w.addWord()
+	if !w.GraphemeAware {
+		for _, c := range s {
+			w.process(c, string(c), w.runeWidth(c))
 		}
-		w.wroteBegin = true
-		if c == '\x1B' {
-			// ANSI escape sequence
-			_, _ = w.word.WriteRune(c)
-			_, _ = w.lastAnsi.WriteRune(c)
-			w.ansi = true
-			w.newArgument = true
-		} else if w.ansi {
-
-			// ignore leading zeros but remember single ones.
-			if c == '0' && w.newArgument {
-				w.leadingZero = true
-				continue
-			}
-			w.newArgument = false
-			// if a digit other then zero is encountered reset leading zero since we can ignore the leading zeroes if there where any.
-			if inGroup([]rune{'1', '2', '3', '4', '5', '6', '7', '8', '9'}, c) {
-				w.leadingZero = false
-			}
+		return len(b), nil
+	}
 
-			// check if new ANSI-argument starts
-			if inGroup([]rune{'[', ';'}, c) {
-				w.newArgument = true
-				// if w.leadingZero is here, we know that its a valid zero => reset and restart sequence.
-				if w.leadingZero {
-					// since we are still in the middle of the sequence and have reset the last ansi, we have to restart a new sequence:
-					w.lastAnsi.Reset()
-					_, _ = w.lastAnsi.WriteString("\x1B[")
-					w.leadingZero = false
-					_, _ = w.word.WriteString("0m\x1B[")
-					// "\x1B[31;0;32m" => "\x1B[31;0m\x1B[32m"
-					continue // dont write "replace" semicolon
-				}
+	ansi.Graphemes(s, w.runeWidth, func(cluster string, runes []rune, width int) {
+		if len(runes) == 1 || w.ansi || w.osc || isSeparator(runes[0]) || inGroup(w.Newline, runes[0]) {
+			// single runes, clusters glued to an ANSI sequence and ones
+			// starting with a line break, like "\r\n", or another
+			// control or space are processed rune by rune
+			for _, c := range runes {
+				w.process(c, string(c), w.runeWidth(c))
 			}
+			return
+		}
 
-			_, _ = w.lastAnsi.WriteRune(c)
+		w.process(runes[0], cluster, width)
+	})
 
-			if (c >= 0x40 && c <= 0x5a) || (c >= 0x61 && c <= 0x7a) {
-				// dont restart lastAnsi since its a end of a sequence. (not in the middle of one)
-				if w.leadingZero {
-					_, _ = w.word.WriteRune('0')
+	return len(b), nil
+}
 
-					w.lastAnsi.Reset()
-					w.leadingZero = false
-				}
-				// ANSI sequence terminated
-				w.ansi = false
+// process handles a single character of the input. c is its first rune, while
+// cluster holds all of its runes and width its printable width.
+func (w *WordWrap) process(c rune, cluster string, width int) {
+	if !w.prefixed {
+		w.addLinePrefix()
+	}
+	w.restartAnsi()
+	if w.osc {
+		// strings such as hyperlinks are zero-width and never get broken
+		_, _ = w.word.WriteString(cluster)
+		if c == '\a' || (w.oscEsc && c == '\\') {
+			w.osc = false
+			if len(w.SequenceMatchers) > 0 {
+				// the sequence may have taken up some cells
+				w.wrapWord()
 			}
-
-			_, _ = w.word.WriteRune(c)
-
-		} else if inGroup(w.Newline, c) {
-			// end of current line
-			// see if we can add the content of the space buffer to the current line
-			if w.word.Len() == 0 {
-				if w.lineLen+w.space.Len() > w.Limit {
-					w.lineLen = 0
-				} else {
-					// preserve whitespace
-					_, _ = w.buf.Write(w.space.Bytes())
-				}
-				w.space.Reset()
+		}
+		w.oscEsc = c == '\x1B'
+	} else if c == '\x1B' {
+		// ANSI escape sequence
+		_, _ = w.seq.WriteRune(c)
+		w.ansi = true
+	} else if w.ansi && w.seq.Len() == 1 && inGroup(stringSequences, c) {
+		// operating system commands and the like are kept as they are
+		w.oscStart = w.word.Len()
+		_, _ = w.word.Write(w.seq.Bytes())
+		_, _ = w.word.WriteRune(c)
+		w.seq.Reset()
+		w.ansi = false
+		w.osc = true
+		w.oscEsc = false
+	} else if w.ansi {
+		_, _ = w.seq.WriteRune(c)
+		if (c >= 0x40 && c <= 0x5a) || (c >= 0x61 && c <= 0x7a) {
+			// ANSI sequence terminated
+			w.ansi = false
+			w.addSequence()
+		}
+	} else if inGroup(w.Newline, c) {
+		// end of current line
+		// see if we can add the content of the space buffer to the current line
+		if w.word.Len() == 0 {
+			if w.lineLen+w.space.Len() > w.Limit {
+				w.lineLen = 0
+			} else {
+				// preserve whitespace
+				w.lineLen += w.space.Len()
+				_, _ = w.buf.Write(w.space.Bytes())
+				w.markSpaced(w.space.Len())
+				w.updateMaxWidth()
 			}
+			w.space.Reset()
+		}
 
+		w.addWord()
+		w.addNewLine(false)
+		w.autoIndent = ""
+		w.indented = false
+	} else if w.ReplaceControl && isControl(c) {
+		// stray control character
+		for _, r := range w.ControlReplace {
+			if !isControl(r) {
+				w.process(r, string(r), w.runeWidth(r))
+			}
+		}
+	} else if isBidiControl(c) {
+		// zero-width, but kept with the following characters
+		_, _ = w.word.WriteString(cluster)
+	} else if c == shy {
+		// optional breakpoint, only taken if a hyphen still fits
+		if !w.HardWrap && w.word.Len() > 0 &&
+			w.lineLen+w.space.Len()+w.wordWidth()+1 <= w.limit() {
+			w.addSpace()
 			w.addWord()
-			w.addNewLine()
-		} else if unicode.IsSpace(c) {
-			// end of current word
-			w.addWord()
-			_, _ = w.space.WriteRune(c)
-		} else if inGroup(w.Breakpoints, c) {
-			// valid breakpoint
+			w.hyphen = true
+		}
+	} else if c == zwsp {
+		// invisible breakpoint
+		if w.word.Len() > 0 {
 			w.addSpace()
 			w.addWord()
-			_, _ = w.word.WriteRune(c)
+		}
+	} else if inGroup(w.ConsumingBreakpoints, c) {
+		// breakpoint dropped if the line gets broken at it
+		w.addSpace()
+		w.addWord()
+		if w.lineLen+width > w.limit() {
+			w.addNewLine(true)
+		} else {
+			w.consumed = cluster
+			w.consumedAt = w.buf.Len()
+			w.consumedWidth = width
+			_, _ = w.buf.WriteString(cluster)
+			w.lineLen += width
+			w.updateMaxWidth()
+		}
+	} else if w.WordBoundaryFunc != nil && !w.isBoundary(c) {
+		// part of the current word
+		w.addRune(c, cluster, width)
+	} else if unicode.IsSpace(c) && c != nbsp {
+		// end of current word
+		w.addWord()
 
-			// Wrap line if the breakpoint would exceed the Limit
-			if w.HardWrap && w.lineLen+w.space.Len()+runewidth.RuneWidth(c) > w.Limit {
-				w.addNewLine()
+		switch {
+		case w.lineLen == 0 && w.TrimIndent:
+			// drop the indentation
+		case w.lineLen > 0 && w.CollapseSpaces:
+			if w.space.Len() == 0 {
+				_, _ = w.space.WriteRune(' ')
 			}
+		case c == '\t' && w.TabWidth > 0:
+			// advance to the next tab stop
+			_, _ = w.space.WriteString(strings.Repeat(" ", w.tabSize()))
+		default:
+			_, _ = w.space.WriteRune(c)
+		}
+		if !w.indented {
+			w.autoIndent = w.space.String()
+		}
+	} else if w.WordBoundaryFunc == nil && w.isBreakpoint(c) {
+		// valid breakpoint
+		if !w.HardWrap {
+			// the breakpoint ends the current word, which moves to the
+			// next line along with it if needed
+			w.addRune(c, cluster, width)
+			// the line may just have got wrapped
+			w.restartAnsi()
+			w.addWord()
+			return
+		}
+
+		w.addSpace()
+		w.addWord()
+		_, _ = w.word.WriteString(cluster)
+
+		// Wrap line if the breakpoint would exceed the Limit
+		if w.lineLen+w.space.Len()+width > w.limit() {
+			w.addNewLine(true)
+		}
 
-			// treat breakpoint as single character length words
+		// treat breakpoint as single character length words
+		w.addWord()
+	} else {
+		if w.CJKRules && w.breaksBefore(c) {
+			// wide characters are words on their own, unless that would
+			// break the line-breaking rules
 			w.addWord()
-		} else if w.HardWrap && w.lineLen+w.word.PrintableRuneWidth()+runewidth.RuneWidth(c)+w.space.Len() == w.Limit {
-			// Word is at the limit -> begin new word
-			_, _ = w.word.WriteRune(c)
+		} else if inGroup(w.BreakBefore, c) || w.WordBoundaryFunc != nil {
+			// begin a new word, which may go onto the next line
 			w.addWord()
-		} else {
-			// any other character
-			_, _ = w.word.WriteRune(c)
-
-			// add a line break if the current word would exceed the line's
-			// character limit
-			if w.lineLen+w.space.Len()+w.word.PrintableRuneWidth() > w.Limit &&
-				w.word.PrintableRuneWidth() < w.Limit {
-				w.addNewLine()
+		}
+		w.addRune(c, cluster, width)
+	}
+}
+
+// restartAnsi restarts the styling after a line break, once there's more text.
+func (w *WordWrap) restartAnsi() {
+	if !w.wroteBegin && !w.ansi && !w.NoAnsiReset && w.styled() {
+		_, _ = w.buf.Write(w.lastAnsi.Bytes())
+	}
+	w.wroteBegin = true
+}
+
+// addRune adds a character to the current word, wrapping it if needed.
+func (w *WordWrap) addRune(c rune, cluster string, width int) {
+	w.lastRune = c
+	w.indented = true
+
+	if w.HardWrap && !(w.CJKRules && w.isWide(c)) &&
+		w.lineLen+w.wordWidth()+width+w.space.Len() == w.limit() {
+		// Word is at the limit -> begin new word
+		_, _ = w.word.WriteString(cluster)
+		w.addWord()
+	} else {
+		if (w.BreakLongWords || w.Overflow == OverflowBreakAtBreakpoints) &&
+			w.wordWidth() > 0 && w.wordWidth()+width > w.limit() {
+			// the word doesn't fit on any line, so break it
+			w.addWord()
+			w.addNewLine(true)
+		}
+
+		// any other character
+		_, _ = w.word.WriteString(cluster)
+
+		w.wrapWord()
+	}
+}
+
+// isBoundary reports whether there's a word boundary in front of c, according
+// to the WordBoundaryFunc.
+func (w *WordWrap) isBoundary(c rune) bool {
+	prev := w.prevRune
+	w.prevRune = c
+	return w.WordBoundaryFunc(prev, c)
+}
+
+// wrapWord adds a line break if the pending word would exceed the line's
+// character limit.
+func (w *WordWrap) wrapWord() {
+	if w.Overflow == OverflowBreak {
+		// wait for the end of the word, unless it has to be broken
+		if w.overflow || w.wordWidth() > w.limit() {
+			w.breakWord()
+		}
+		return
+	}
+
+	if w.lineLen+w.space.Len()+w.wordWidth() > w.limit() &&
+		w.wordWidth() <= w.limit() &&
+		w.lineLen+w.space.Len() > w.indentLen {
+		w.addNewLine(true)
+	}
+}
+
+// addSequence adds the pending escape sequence to the word. Only SGR
+// sequences are remembered, so they can be restarted after line breaks.
+func (w *WordWrap) addSequence() {
+	seq := w.seq.Bytes()
+	defer w.seq.Reset()
+
+	if w.ansi || !bytes.HasPrefix(seq, []byte("\x1B[")) || seq[len(seq)-1] != 'm' {
+		// other or incomplete sequences are kept as they are
+		_, _ = w.word.Write(seq)
+		return
+	}
+
+	if !w.NormalizeAnsi {
+		if resetsSGR(seq) {
+			w.lastAnsi.Reset()
+		}
+		_, _ = w.lastAnsi.Write(seq)
+		_, _ = w.word.Write(seq)
+		return
+	}
+
+	w.newArgument = true
+	for _, c := range string(seq) {
+		w.addSGRRune(c)
+	}
+}
+
+// resetsSGR reports whether an SGR sequence resets all attributes, by any of
+// its arguments being zero or empty.
+func resetsSGR(seq []byte) bool {
+	for _, p := range strings.Split(string(seq[2:len(seq)-1]), ";") {
+		if strings.Trim(p, "0") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// styled reports whether the remembered ANSI sequences leave any styling
+// active, so it has to be reset at the end of the line and restarted on the
+// next one. Sequences which only turn off attributes, such as "\x1B[m" or
+// "\x1B[31m\x1B[39m", are skipped, unless they hold codes ansi.Style doesn't
+// know about.
+func (w *WordWrap) styled() bool {
+	if w.lastAnsi.Len() == 0 {
+		return false
+	}
+
+	s := w.lastAnsi.String()
+	if !ansi.ActiveStyle(s).IsZero() {
+		return true
+	}
+
+	var unknown bool
+	ansi.Parse([]byte(s), func(seq, _ []byte) {
+		if len(seq) < 3 {
+			unknown = true
+			return
+		}
+		for _, p := range strings.Split(string(seq[2:len(seq)-1]), ";") {
+			if i := strings.Index(p, ":"); i >= 0 {
+				// sub-parameters, only known for underlines and colors
+				p = p[:i]
+				if p != "4" && p != "38" && p != "48" {
+					unknown = true
+				}
+				continue
+			}
+			if p == "" {
+				continue
+			}
+			code, err := strconv.Atoi(p)
+			if err != nil || !ansi.KnownSGR(code) {
+				unknown = true
 			}
 		}
+	})
+	return unknown
+}
+
+// addSGRRune adds a rune of an SGR sequence to the word, removing leading
+// zeros of its arguments.
+func (w *WordWrap) addSGRRune(c rune) {
+	// ignore leading zeros but remember single ones.
+	if c == '0' && w.newArgument {
+		w.leadingZero = true
+		return
+	}
+	w.newArgument = false
+	// if a digit other then zero is encountered reset leading zero since we can ignore the leading zeroes if there where any.
+	if inGroup([]rune{'1', '2', '3', '4', '5', '6', '7', '8', '9'}, c) {
+		w.leadingZero = false
 	}
 
-	return len(b), nil
+	// check if new ANSI-argument starts
+	if inGroup([]rune{'[', ';'}, c) {
+		w.newArgument = true
+		// if w.leadingZero is here, we know that its a valid zero => reset and restart sequence.
+		if w.leadingZero {
+			// since we are still in the middle of the sequence and have reset the last ansi, we have to restart a new sequence:
+			w.lastAnsi.Reset()
+			_, _ = w.lastAnsi.WriteString("\x1B[")
+			w.leadingZero = false
+			_, _ = w.word.WriteString("0m\x1B[")
+			// "\x1B[31;0;32m" => "\x1B[31;0m\x1B[32m"
+			return // dont write "replace" semicolon
+		}
+	}
+
+	_, _ = w.lastAnsi.WriteRune(c)
+
+	if c == 'm' {
+		// dont restart lastAnsi since its a end of a sequence. (not in the middle of one)
+		if w.leadingZero {
+			_, _ = w.word.WriteRune('0')
+
+			w.lastAnsi.Reset()
+			w.leadingZero = false
+		}
+	}
+
+	_, _ = w.word.WriteRune(c)
 }
 
 // Close will finish the word-wrap operation. Always call it before trying to
 // retrieve the final result.
 func (w *WordWrap) Close() error {
-	if w.PreserveSpaces {
-		w.addSpace()
+	// nothing is pending when passing through
+	if w.Limit != 0 {
+		if w.seq.Len() > 0 {
+			// an incomplete escape sequence would leave the terminal
+			// waiting for its end, so it's dropped
+			w.seq.Reset()
+		}
+		w.ansi = false
+		if w.osc {
+			// the same goes for an unterminated string sequence
+			w.word.Truncate(w.oscStart)
+			w.osc = false
+			w.oscEsc = false
+		}
+		if w.PreserveSpaces {
+			w.addSpace()
+		}
+		w.addWord()
+	}
+
+	w.trimLinePrefix()
+	if w.TrailingNewline && w.buf.Len() > 0 && w.buf.Bytes()[w.buf.Len()-1] != '\n' {
+		w.addNewLine(false)
+		w.trimLinePrefix()
+	}
+	if w.buf.Len() > 0 && w.buf.Bytes()[w.buf.Len()-1] != '\n' {
+		// the last line
+		w.emitLine()
 	}
-	w.addWord()
 
 	return nil
 }
 
+// Reset discards the wrapped result and all pending state, so the
+// wordwrapper can be reused with its current settings.
+func (w *WordWrap) Reset() {
+	w.buf.Reset()
+	w.space.Reset()
+	w.word.Reset()
+	w.seq.Reset()
+	w.lastAnsi.Reset()
+
+	w.lineLen = 0
+	w.lineStart = 0
+	w.indentLen = 0
+	w.carried = ansi.Style{}
+	w.maxWidth = 0
+	w.newlines = 0
+	w.ansi = false
+	w.osc = false
+	w.oscEsc = false
+	w.oscStart = 0
+	w.lastRune = 0
+	w.prevRune = 0
+	w.lastCR = false
+	w.hyphen = false
+	w.overflow = false
+	w.overflown = false
+	w.prefixed = false
+	w.midLine = false
+	w.autoIndent = ""
+	w.indented = false
+	w.consumed = ""
+	w.spaced = 0
+	w.paraBreaks = 0
+	w.paraSpace = w.paraSpace[:0]
+	w.paraStarted = false
+	w.wroteBegin = false
+	w.newArgument = false
+	w.leadingZero = false
+}
+
+// SetLimit changes the limit for all content written afterwards, e.g. when the
+// terminal got resized. Completed lines are kept as they are, while the current
+// line continues from its current position. If the current line already
+// exceeds the new limit, its pending word gets moved to the next line. Setting
+// a limit of 0 adds all pending content to the current line.
+func (w *WordWrap) SetLimit(limit int) {
+	if limit <= 0 {
+		w.addWord()
+		w.addSpace()
+		w.Limit = limit
+		return
+	}
+
+	w.Limit = limit
+
+	if w.lineLen > 0 && w.lineLen+w.space.Len()+w.wordWidth() > w.limit() {
+		w.addNewLine(true)
+	}
+}
+
+// Remaining returns the amount of cells which still fit on the current line
+// before it gets wrapped, taking pending words and spaces into account. It
+// returns 0 if the line is full or no limit is set.
+func (w *WordWrap) Remaining() int {
+	if w.Limit <= 0 {
+		return 0
+	}
+
+	n := w.limit() - w.lineLen - w.space.Len() - w.wordWidth()
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// MaxWidth returns the printable width of the widest line produced so far.
+// Spaces ending a line don't count, even if they're kept, e.g. due to
+// PreserveSpaces. Make sure to have closed the wordwrapper, before calling it.
+func (w *WordWrap) MaxWidth() int {
+	return w.maxWidth
+}
+
+// HadOverflow reports whether any line produced so far exceeds the limit, e.g.
+// because of a word wider than the limit, which got put on its own line.
+// Make sure to have closed the wordwrapper, before calling it.
+func (w *WordWrap) HadOverflow() bool {
+	return w.overflown
+}
+
+// LineCount returns the amount of lines produced so far. A trailing newline
+// does not start another line.
+// Make sure to have closed the wordwrapper, before calling it.
+func (w *WordWrap) LineCount() int {
+	if w.buf.Len() > 0 && w.buf.Bytes()[w.buf.Len()-1] != '\n' {
+		return w.newlines + 1
+	}
+	return w.newlines
+}
+
+// Lines returns the word-wrapped result split into lines. A trailing newline
+// does not start another line, and escape sequences are never split.
+// Make sure to have closed the wordwrapper, before calling it.
+func (w *WordWrap) Lines() []string {
+	var lines []string
+	var line []byte
+
+	ansi.Parse(w.buf.Bytes(), func(seq, text []byte) {
+		line = append(line, seq...)
+		for {
+			i := bytes.IndexByte(text, '\n')
+			if i < 0 {
+				break
+			}
+			lines = append(lines, string(append(line, text[:i]...)))
+			line = line[:0]
+			text = text[i+1:]
+		}
+		line = append(line, text...)
+	})
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+
+	return lines
+}
+
+// Grow grows the capacity of the buffer holding the word-wrapped result, if
+// necessary, to guarantee space for another n bytes, e.g. the expected size of
+// the result. Like bytes.Buffer.Grow, it panics if n is negative.
+func (w *WordWrap) Grow(n int) {
+	w.buf.Grow(n)
+}
+
 // Bytes returns the word-wrapped result as a byte slice.
 // Make sure to have closed the wordwrapper, before calling it.
 func (w *WordWrap) Bytes() []byte {
@@ -1,7 +1,12 @@
 package wordwrap
 
 import (
+	"strings"
 	"testing"
+	"unicode"
+
+	runewidth "github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/ansi"
 )
 
 func TestWordWrap(t *testing.T) {
@@ -40,6 +45,26 @@ func TestWordWrap(t *testing.T) {
 			4,
 			true,
 		},
+		// A word exactly as wide as the limit is wrapped:
+		{
+			"a 你好",
+			"a\n你好",
+			4,
+			true,
+		},
+		// No-break spaces glue words together:
+		{
+			"size: 100\u00A0MB",
+			"size:\n100\u00A0MB",
+			10,
+			true,
+		},
+		{
+			"size: 100 MB",
+			"size: 100\nMB",
+			10,
+			true,
+		},
 		// A hyphen is a valid breakpoint:
 		{
 			"foo-foobar",
@@ -47,6 +72,13 @@ func TestWordWrap(t *testing.T) {
 			4,
 			true,
 		},
+		// A breakpoint following a word that is too long:
+		{
+			"foobarbaz-x",
+			"foobarbaz-\nx",
+			5,
+			true,
+		},
 		// Space buffer needs to be emptied before breakpoints:
 		{
 			"foo --bar",
@@ -310,3 +342,3183 @@ func TestHarWrapShort(t *testing.T) {
 		t.Errorf("From input expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, out)
 	}
 }
+
+func TestWordWrapGraphemeAware(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		HardWrap bool
+	}{
+		// Flags are made of two regional indicators and are wide:
+		{
+			"🇩🇪 🇫🇷 🇮🇹",
+			"🇩🇪\n🇫🇷\n🇮🇹",
+			3,
+			false,
+		},
+		{
+			"🇩🇪 🇫🇷 🇮🇹",
+			"🇩🇪 🇫🇷\n🇮🇹",
+			5,
+			false,
+		},
+		// ZWJ sequences count as a single glyph:
+		{
+			"ab 👨‍👩‍👧 cd",
+			"ab 👨‍👩‍👧\ncd",
+			5,
+			false,
+		},
+		// Combining vowels don't add to the width:
+		{
+			"नमस्ते नमस्ते",
+			"नमस्ते नमस्ते",
+			9,
+			false,
+		},
+		// Clusters are never split by hard wrapping:
+		{
+			"a👨‍👩‍👧b👨‍👩‍👧",
+			"a👨‍👩‍👧\nb👨‍👩‍👧",
+			3,
+			true,
+		},
+		// "\r\n" is a single cluster, but still breaks the line:
+		{
+			"foo\r\nbar baz",
+			"foo\r\nbar\nbaz",
+			4,
+			false,
+		},
+		// ANSI sequences are still recognized:
+		{
+			"\x1B[31m👨‍👩‍👧\x1B[0m 👨‍👩‍👧",
+			"\x1B[31m👨‍👩‍👧\x1B[0m\n👨‍👩‍👧",
+			3,
+			false,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.GraphemeAware = true
+		f.HardWrap = tc.HardWrap
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapVariationSelectors(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// VS16 requests the wide emoji presentation:
+		{
+			"\u231A\uFE0F\u231A\uFE0F a",
+			"\u231A\uFE0F\u231A\uFE0F a",
+			6,
+		},
+		{
+			"\u263A\uFE0F \u263A\uFE0F \u263A\uFE0F",
+			"\u263A\uFE0F \u263A\uFE0F\n\u263A\uFE0F",
+			5,
+		},
+		// VS15 requests the narrow text presentation:
+		{
+			"\u231A\uFE0E\u231A\uFE0E foo",
+			"\u231A\uFE0E\u231A\uFE0E foo",
+			6,
+		},
+		{
+			"\u231A\uFE0E\u231A\uFE0E foo",
+			"\u231A\uFE0E\u231A\uFE0E\nfoo",
+			5,
+		},
+	}
+
+	for i, tc := range tt {
+		for _, graphemes := range []bool{false, true} {
+			f := NewWriter(tc.Limit)
+			f.GraphemeAware = graphemes
+
+			_, err := f.Write([]byte(tc.Input))
+			if err != nil {
+				t.Error(err)
+			}
+			f.Close()
+
+			if f.String() != tc.Expected {
+				t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+			}
+		}
+	}
+}
+
+func TestWordWrapMaxWidth(t *testing.T) {
+	tt := []struct {
+		Input      string
+		Expected   int
+		Limit      int
+		HardWrap   bool
+		TabReplace string
+	}{
+		// Empty input has no width:
+		{
+			"",
+			0,
+			5,
+			false,
+			"",
+		},
+		// The widest line wins:
+		{
+			"foo barbaz qux",
+			6,
+			7,
+			false,
+			"",
+		},
+		// Over-limit words are measured as they are:
+		{
+			"foo barbazqux",
+			9,
+			4,
+			false,
+			"",
+		},
+		// ANSI sequences don't count:
+		{
+			"\x1B[38;2;249;38;114mfoo\x1B[0m bar",
+			3,
+			4,
+			false,
+			"",
+		},
+		// A trailing newline adds no width:
+		{
+			"foo\nba\n",
+			3,
+			4,
+			false,
+			"",
+		},
+		// Tabs are expanded when hard wrapping:
+		{
+			"\tfoo",
+			6,
+			10,
+			true,
+			"   ",
+		},
+		// Limit of zero passes through:
+		{
+			"foo\nfoobar",
+			6,
+			0,
+			false,
+			"",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.HardWrap = tc.HardWrap
+		f.TabReplace = tc.TabReplace
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.MaxWidth() != tc.Expected {
+			t.Errorf("Test %d, expected max width %d, got %d", i, tc.Expected, f.MaxWidth())
+		}
+	}
+}
+
+func TestWordWrapMaxWidthTrailingSpaces(t *testing.T) {
+	tt := []struct {
+		Input          string
+		Expected       string
+		Limit          int
+		PreserveSpaces bool
+		MaxWidth       int
+	}{
+		{
+			"foo   \nbar",
+			"foo   \nbar",
+			20,
+			false,
+			3,
+		},
+		{
+			"foo bar   baz",
+			"foo \nbar  \n \nbaz",
+			5,
+			true,
+			3,
+		},
+		{
+			"foo    ",
+			"foo    ",
+			20,
+			true,
+			3,
+		},
+		{
+			"foo   \x1B[31m\x1B[0m\nb",
+			"foo   \x1B[31m\x1B[0m\nb",
+			20,
+			true,
+			3,
+		},
+		{
+			"   \n  x",
+			"   \n  x",
+			20,
+			true,
+			3,
+		},
+		// Limit of zero passes through:
+		{
+			"foo   \nbar",
+			"foo   \nbar",
+			0,
+			false,
+			3,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.PreserveSpaces = tc.PreserveSpaces
+		_, _ = f.Write([]byte(tc.Input))
+		_ = f.Close()
+
+		if actual := f.String(); actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+		if f.MaxWidth() != tc.MaxWidth {
+			t.Errorf("Test %d, expected max width %d, got %d", i, tc.MaxWidth, f.MaxWidth())
+		}
+	}
+}
+
+func TestWordWrapHadOverflow(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Limit    int
+		Overflow OverflowMode
+		Expected bool
+	}{
+		{"", 4, OverflowLeave, false},
+		{"foo bar baz", 4, OverflowLeave, false},
+		{"\x1B[31mfoo\x1B[0m bar", 3, OverflowLeave, false},
+		// Words wider than the limit are left on their own line:
+		{"foo foobar baz", 4, OverflowLeave, true},
+		{"你好世界", 4, OverflowLeave, true},
+		// Unless they get broken:
+		{"foo foobar baz", 4, OverflowBreak, false},
+		{"foo foo-bar baz", 4, OverflowBreakAtBreakpoints, false},
+		// Limit of zero passes through:
+		{"foo foobar baz", 0, OverflowLeave, false},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Overflow = tc.Overflow
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.HadOverflow() != tc.Expected {
+			t.Errorf("Test %d, expected overflow to be %t, got %t", i, tc.Expected, f.HadOverflow())
+		}
+
+		f.Reset()
+		if f.HadOverflow() {
+			t.Errorf("Test %d, expected no overflow after a reset", i)
+		}
+	}
+}
+
+func TestWordWrapLineCount(t *testing.T) {
+	tt := []struct {
+		Input        string
+		Expected     int
+		Limit        int
+		KeepNewlines bool
+		HardWrap     bool
+	}{
+		// Empty input has no lines:
+		{
+			"",
+			0,
+			4,
+			true,
+			false,
+		},
+		// Soft breaks start new lines:
+		{
+			"foo bar foo",
+			3,
+			4,
+			true,
+			false,
+		},
+		// A trailing newline does not start another line:
+		{
+			"foo bar foo\n",
+			3,
+			4,
+			true,
+			false,
+		},
+		// Explicit breaks are counted:
+		{
+			"\nfoo bar\n\n\nfoo\n",
+			6,
+			4,
+			true,
+			false,
+		},
+		// Unless they get collapsed:
+		{
+			"\nfoo bar\n\n\nfoo\n",
+			3,
+			4,
+			false,
+			false,
+		},
+		// Hard wraps are counted:
+		{
+			"foobarfoobar",
+			4,
+			3,
+			true,
+			true,
+		},
+		// Limit of zero passes through:
+		{
+			"foo\nbar",
+			2,
+			0,
+			true,
+			false,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.KeepNewlines = tc.KeepNewlines
+		f.HardWrap = tc.HardWrap
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.LineCount() != tc.Expected {
+			t.Errorf("Test %d, expected %d lines, got %d", i, tc.Expected, f.LineCount())
+		}
+	}
+}
+
+func TestWordWrapReset(t *testing.T) {
+	f := NewWriter(4)
+	f.HardWrap = true
+
+	_, _ = f.Write([]byte("\x1B[31mfoo bar\x1B"))
+	f.Reset()
+
+	_, err := f.Write([]byte("foobar"))
+	if err != nil {
+		t.Error(err)
+	}
+	f.Close()
+
+	expected := "foob\nar"
+	if f.String() != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, f.String())
+	}
+	if f.MaxWidth() != 4 || f.LineCount() != 2 {
+		t.Errorf("expected max width 4 and 2 lines, got %d and %d", f.MaxWidth(), f.LineCount())
+	}
+}
+
+// go test -bench=BenchmarkWordWrap -benchmem -count=4
+func BenchmarkWordWrapNew(b *testing.B) {
+	buf := []byte("\x1B[38;2;249;38;114mthe quick brown fox\x1B[0m jumps over the lazy dog")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f := NewWriter(10)
+		_, _ = f.Write(buf)
+		_ = f.Close()
+	}
+}
+
+func BenchmarkWordWrapReset(b *testing.B) {
+	buf := []byte("\x1B[38;2;249;38;114mthe quick brown fox\x1B[0m jumps over the lazy dog")
+	f := NewWriter(10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		_, _ = f.Write(buf)
+		_ = f.Close()
+	}
+}
+
+func BenchmarkWordWrapLarge(b *testing.B) {
+	buf := []byte(strings.Repeat("\x1B[31mthe quick brown fox\x1B[0m jumps over the lazy dog ", 1000))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f := NewWriter(40)
+		_, _ = f.Write(buf)
+		_ = f.Close()
+	}
+}
+
+func BenchmarkWordWrapGrow(b *testing.B) {
+	buf := []byte(strings.Repeat("\x1B[31mthe quick brown fox\x1B[0m jumps over the lazy dog ", 1000))
+	size := len(String(string(buf), 40))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f := NewWriter(40)
+		f.Grow(size)
+		_, _ = f.Write(buf)
+		_ = f.Close()
+	}
+}
+
+func BenchmarkWordWrapBytes(b *testing.B) {
+	buf := []byte("\x1B[38;2;249;38;114mthe quick brown fox\x1B[0m jumps over the lazy dog")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Bytes(buf, 10)
+	}
+}
+
+func BenchmarkWordWrapAppendWrapped(b *testing.B) {
+	buf := []byte("\x1B[38;2;249;38;114mthe quick brown fox\x1B[0m jumps over the lazy dog")
+	var dst []byte
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = AppendWrapped(dst[:0], buf, 10)
+	}
+}
+
+func TestWordWrapGrow(t *testing.T) {
+	f := NewWriter(10)
+	f.Grow(64)
+
+	_, err := f.Write([]byte("the quick brown fox"))
+	if err != nil {
+		t.Error(err)
+	}
+	f.Close()
+
+	if expected := "the quick\nbrown fox"; f.String() != expected {
+		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, f.String())
+	}
+	if c := cap(f.Bytes()); c < 64 {
+		t.Errorf("expected a capacity of at least 64 bytes, got %d", c)
+	}
+}
+
+func TestAppendWrapped(t *testing.T) {
+	dst := []byte("> ")
+	dst = AppendWrapped(dst, []byte("\x1B[31mfoo bar\x1B[0m"), 4)
+	dst = AppendWrapped(dst, []byte(" baz qux"), 4)
+
+	expected := "> \x1B[31mfoo\x1B[0m\n\x1B[31mbar\x1B[0m baz\nqux"
+	if string(dst) != expected {
+		t.Errorf("expected:\n\n`%q`\n\nActual Output:\n\n`%q`", expected, dst)
+	}
+}
+
+func TestWordWrapPreserveTrailingSpaces(t *testing.T) {
+	tt := []struct {
+		Input        string
+		Expected     string
+		KeepNewlines bool
+	}{
+		// Trailing spaces:
+		{
+			"foo   ",
+			"foo   ",
+			true,
+		},
+		{
+			"foo   ",
+			"foo   ",
+			false,
+		},
+		// Trailing tabs:
+		{
+			"foo\t\t",
+			"foo\t\t",
+			true,
+		},
+		{
+			"foo\t\t",
+			"foo\t\t",
+			false,
+		},
+		// Mixed whitespace:
+		{
+			"foo \t ",
+			"foo \t ",
+			true,
+		},
+		{
+			"foo \t \n",
+			"foo \t ",
+			false,
+		},
+		// Trailing whitespace after a styled word:
+		{
+			"\x1B[31mfoo \t",
+			"\x1B[31mfoo \t",
+			false,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(10)
+		f.PreserveSpaces = true
+		f.KeepNewlines = tc.KeepNewlines
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapBreakpointFunc(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Func     func(rune) bool
+	}{
+		// Default breakpoints are used without a function:
+		{
+			"foo-bar/baz",
+			"foo-\nbar/baz",
+			nil,
+		},
+		// Any dash or slash:
+		{
+			"foo–bar/baz",
+			"foo–\nbar/\nbaz",
+			func(r rune) bool {
+				return unicode.Is(unicode.Pd, r) || r == '/'
+			},
+		},
+		// The function takes precedence over the breakpoints:
+		{
+			"foo-bar/baz",
+			"foo-bar/\nbaz",
+			func(r rune) bool {
+				return r == '/'
+			},
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(5)
+		f.BreakpointFunc = tc.Func
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+// stripANSI removes all ANSI sequences from s.
+func stripANSI(s string) string {
+	var b strings.Builder
+	var inSeq bool
+	for _, c := range s {
+		if c == ansi.Marker {
+			inSeq = true
+		} else if inSeq {
+			if ansi.IsTerminator(c) {
+				inSeq = false
+			}
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// completeANSI reports whether all ANSI sequences in s are terminated before
+// the next sequence or line break starts.
+func completeANSI(s string) bool {
+	var inSeq bool
+	for _, c := range s {
+		if inSeq {
+			if c == ansi.Marker || c == '\n' {
+				return false
+			}
+			if ansi.IsTerminator(c) {
+				inSeq = false
+			}
+		} else if c == ansi.Marker {
+			inSeq = true
+		}
+	}
+	return !inSeq
+}
+
+func TestHardWrapANSIAtLimit(t *testing.T) {
+	inputs := []string{
+		"\x1b[31mhello world\x1b[0m",
+		"hello\x1b[31m world\x1b[0m",
+		"hell\x1b[31mo world\x1b[0m",
+		"hello\x1b[31mworld\x1b[0m",
+		"abcd\x1b[38;2;249;38;114mefgh\x1b[0mij",
+	}
+
+	for i, in := range inputs {
+		for limit := 1; limit <= 12; limit++ {
+			out := HardWrap(in, limit, "")
+			if !completeANSI(out) {
+				t.Errorf("Test %d, limit %d: partial ANSI sequence in %q", i, limit, out)
+			}
+
+			expected := HardWrap(stripANSI(in), limit, "")
+			if stripped := stripANSI(out); stripped != expected {
+				t.Errorf("Test %d, limit %d: expected:\n\n`%s`\n\nActual Output:\n\n`%s`", i, limit, expected, stripped)
+			}
+		}
+	}
+}
+
+func TestWordWrapLineBreakSuffix(t *testing.T) {
+	tt := []struct {
+		Input          string
+		Expected       string
+		Limit          int
+		HardWrap       bool
+		PreserveSpaces bool
+	}{
+		// The suffix is added to wrapped lines, but not to the last one:
+		{
+			"foo bar baz",
+			"foo↩\nbar↩\nbaz",
+			5,
+			false,
+			false,
+		},
+		// The suffix needs to fit the limit:
+		{
+			"foo bar",
+			"foo↩\nbar",
+			7,
+			false,
+			false,
+		},
+		// Explicit line breaks get no suffix:
+		{
+			"foo\nbar baz",
+			"foo\nbar baz",
+			8,
+			false,
+			false,
+		},
+		// The suffix is placed before the ANSI reset:
+		{
+			"\x1B[31mfoo bar\x1B[0m",
+			"\x1B[31mfoo↩\x1B[0m\n\x1B[31mbar\x1B[0m",
+			5,
+			false,
+			false,
+		},
+		// Hard wrapped lines get the suffix:
+		{
+			"foobarbaz",
+			"foo↩\nbar↩\nbaz",
+			4,
+			true,
+			false,
+		},
+		// Wrapped spaces get the suffix:
+		{
+			"foo       ",
+			"foo↩\n   ↩\n   ↩\n ",
+			4,
+			false,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.LineBreakSuffix = "↩"
+		f.HardWrap = tc.HardWrap
+		f.PreserveSpaces = tc.PreserveSpaces
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapHyperlinks(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// The URL doesn't count towards the width, the link text wraps:
+		{
+			"see \x1B]8;;https://example.com/a/very/long/path\x1B\\the docs\x1B]8;;\x1B\\ now",
+			"see \x1B]8;;https://example.com/a/very/long/path\x1B\\the\ndocs\x1B]8;;\x1B\\ now",
+			8,
+		},
+		{
+			"see \x1B]8;;https://example.com/a/very/long/path\x1B\\the docs\x1B]8;;\x1B\\ now",
+			"see\n\x1B]8;;https://example.com/a/very/long/path\x1B\\the\ndocs\x1B]8;;\x1B\\\nnow",
+			3,
+		},
+		// Links terminated by BEL, containing breakpoints:
+		{
+			"a \x1B]8;;http://x.y/a-b\afoo-bar\x1B]8;;\a",
+			"a \x1B]8;;http://x.y/a-b\afoo-\nbar\x1B]8;;\a",
+			8,
+		},
+		// Styled links:
+		{
+			"\x1B[31m\x1B]8;;http://x.y\x1B\\foo bar\x1B]8;;\x1B\\\x1B[0m",
+			"\x1B[31m\x1B]8;;http://x.y\x1B\\foo\x1B[0m\n\x1B[31mbar\x1B]8;;\x1B\\\x1B[0m",
+			3,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapBreakLongWords(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Words fitting the limit are wrapped as a whole:
+		{
+			"foo bar baz",
+			"foo\nbar\nbaz",
+			5,
+		},
+		// URLs longer than the limit are broken:
+		{
+			"see https://example.com/foo/bar now",
+			"see\nhttps://ex\nample.com/\nfoo/bar\nnow",
+			10,
+		},
+		// Double-width runes:
+		{
+			"你好世界",
+			"你好\n世界",
+			5,
+		},
+		// ANSI sequences:
+		{
+			"\x1B[31mfoobarbaz\x1B[0m",
+			"\x1B[31mfoob\x1B[0m\n\x1B[31marba\x1B[0m\n\x1B[31mz\x1B[0m",
+			4,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.BreakLongWords = true
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapRemaining(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected int
+		Limit    int
+	}{
+		// Empty line:
+		{
+			"",
+			10,
+			10,
+		},
+		// Pending word:
+		{
+			"foo",
+			7,
+			10,
+		},
+		// Pending spaces:
+		{
+			"foo  ",
+			5,
+			10,
+		},
+		// After a soft break:
+		{
+			"foo bar baz",
+			5,
+			8,
+		},
+		// After an explicit break:
+		{
+			"foo\nba",
+			8,
+			10,
+		},
+		// ANSI sequences and double-width runes:
+		{
+			"\x1B[31m你好\x1B[0m",
+			6,
+			10,
+		},
+		// Over-limit words are clamped:
+		{
+			"foobarbaz",
+			0,
+			4,
+		},
+		// No limit:
+		{
+			"foo",
+			0,
+			0,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if f.Remaining() != tc.Expected {
+			t.Errorf("Test %d, expected %d remaining cells, got %d", i, tc.Expected, f.Remaining())
+		}
+	}
+}
+
+func TestWordWrapParagraphMode(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Single line breaks are joined:
+		{
+			"foo\nbar\nbaz",
+			"foo bar baz",
+			20,
+		},
+		// Blank lines separate paragraphs:
+		{
+			"foo\nbar\n\nbaz\nqux",
+			"foo bar\n\nbaz qux",
+			20,
+		},
+		// Runs of blank lines are reduced to one:
+		{
+			"foo\n\n\n\nbar\n \t\n\nbaz",
+			"foo\n\nbar\n\nbaz",
+			20,
+		},
+		// Leading and trailing blank lines are dropped:
+		{
+			"\n\nfoo\nbar\n\n\n",
+			"foo bar",
+			20,
+		},
+		// Lines are trimmed, while spaces within them are kept:
+		{
+			"  foo  bar \n\tbaz  ",
+			"foo  bar baz",
+			20,
+		},
+		// Windows line endings:
+		{
+			"foo\r\nbar\r\n\r\nbaz\r\n",
+			"foo bar\n\nbaz",
+			20,
+		},
+		// Paragraphs are wrapped:
+		{
+			"the quick\nbrown fox\n\njumps over\nthe lazy dog",
+			"the quick\nbrown fox\n\njumps over\nthe lazy\ndog",
+			10,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.ParagraphMode = true
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+
+		// written in chunks
+		f = NewWriter(tc.Limit)
+		f.ParagraphMode = true
+		for j := 0; j < len(tc.Input); j++ {
+			if _, err := f.Write([]byte{tc.Input[j]}); err != nil {
+				t.Error(err)
+			}
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected chunked:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapNormalizeNewlines(t *testing.T) {
+	tt := []struct {
+		Input        []string
+		Expected     string
+		Limit        int
+		KeepNewlines bool
+	}{
+		// Windows line endings:
+		{
+			[]string{"a\r\nb"},
+			"a\nb",
+			10,
+			true,
+		},
+		// Lone carriage returns:
+		{
+			[]string{"a\rb"},
+			"a\nb",
+			10,
+			true,
+		},
+		// Mixed line endings:
+		{
+			[]string{"foo bar\r\nbaz\rqux\nquux"},
+			"foo\nbar\nbaz\nqux\nquux",
+			5,
+			true,
+		},
+		// Line endings split between writes:
+		{
+			[]string{"a\r", "\nb\r", "\r\n"},
+			"a\nb\n\n",
+			10,
+			true,
+		},
+		// Joined lines don't keep carriage returns:
+		{
+			[]string{"foo\r\nbar\r\nbaz"},
+			"foo bar\nbaz",
+			7,
+			false,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.KeepNewlines = tc.KeepNewlines
+		f.NormalizeNewlines = true
+
+		for _, in := range tc.Input {
+			_, err := f.Write([]byte(in))
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapFormFeed(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Breaks   int
+		Newline  []rune
+	}{
+		// Form feeds are dropped by default:
+		{
+			"page one\fpage two",
+			"page onepage two",
+			20,
+			0,
+			nil,
+		},
+		{
+			"page one\n\fpage two",
+			"page one\npage two",
+			20,
+			0,
+			nil,
+		},
+		// They may break lines:
+		{
+			"page one\fpage two",
+			"page one\npage two",
+			20,
+			1,
+			nil,
+		},
+		{
+			"page one\fpage two",
+			"page\none\n\npage\ntwo",
+			5,
+			2,
+			nil,
+		},
+		{
+			"\x1B[31mpage one\fpage two\x1B[0m",
+			"\x1B[31mpage one\x1B[0m\n\x1B[31m\x1B[0m\n\x1B[31mpage two\x1B[0m",
+			20,
+			2,
+			nil,
+		},
+		// Or be newlines themselves:
+		{
+			"page one\fpage two",
+			"page one\npage two",
+			20,
+			0,
+			[]rune{'\n', '\f'},
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.FormFeedBreaks = tc.Breaks
+		if tc.Newline != nil {
+			f.Newline = tc.Newline
+		}
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapNoAnsiReset(t *testing.T) {
+	tt := []struct {
+		Input       string
+		Expected    string
+		NoAnsiReset bool
+	}{
+		// Styles are reset and restored by default:
+		{
+			"\x1B[31mfoo bar\x1B[0m baz",
+			"\x1B[31mfoo\x1B[0m\n\x1B[31mbar\x1B[0m\nbaz",
+			false,
+		},
+		// Styles are kept open:
+		{
+			"\x1B[31mfoo bar\x1B[0m baz",
+			"\x1B[31mfoo\nbar\x1B[0m\nbaz",
+			true,
+		},
+		// Line breaks of the input:
+		{
+			"\x1B[1mfoo\nbar",
+			"\x1B[1mfoo\nbar",
+			true,
+		},
+		{
+			"\x1B[1mfoo\nbar",
+			"\x1B[1mfoo\x1B[0m\n\x1B[1mbar",
+			false,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(4)
+		f.NoAnsiReset = tc.NoAnsiReset
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapTabWidth(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		HardWrap bool
+	}{
+		// Tab at column 0:
+		{
+			"\tfoo",
+			"    foo",
+			20,
+			false,
+		},
+		// Tab at column 3:
+		{
+			"foo\tbar",
+			"foo bar",
+			20,
+			false,
+		},
+		// Tab at column 8:
+		{
+			"foobarba\tz",
+			"foobarba    z",
+			20,
+			false,
+		},
+		// Columns are aligned across lines:
+		{
+			"a\tb\tc\nfoo\tbar\tbaz",
+			"a   b   c\nfoo bar baz",
+			20,
+			false,
+		},
+		// Column is counted from the start of wrapped lines:
+		{
+			"foo bar\tb",
+			"foo\nbar b",
+			6,
+			false,
+		},
+		// Tabs don't advance beyond the limit:
+		{
+			"foobarba\tz",
+			"foobarba\nz",
+			10,
+			false,
+		},
+		// Hard wrapping uses the tab width instead of TabReplace:
+		{
+			"ab\tcdefgh",
+			"ab  cdef\ngh",
+			8,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.TabWidth = 4
+		f.HardWrap = tc.HardWrap
+		f.TabReplace = "!"
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapCollapseSpaces(t *testing.T) {
+	tt := []struct {
+		Input          string
+		Expected       string
+		Limit          int
+		CollapseSpaces bool
+		TrimIndent     bool
+	}{
+		// Spaces are kept by default:
+		{
+			"foo    bar",
+			"foo    bar",
+			20,
+			false,
+			false,
+		},
+		// Runs of whitespace are squeezed:
+		{
+			"foo    bar \t baz",
+			"foo bar baz",
+			20,
+			true,
+			false,
+		},
+		// Collapsed spaces are wrapped:
+		{
+			"foo    bar     baz",
+			"foo bar\nbaz",
+			7,
+			true,
+			false,
+		},
+		// Indentation is kept:
+		{
+			"  foo   bar\n    baz",
+			"  foo bar\n    baz",
+			20,
+			true,
+			false,
+		},
+		// Indentation is dropped:
+		{
+			"  foo   bar\n \tbaz",
+			"foo   bar\nbaz",
+			20,
+			false,
+			true,
+		},
+		{
+			"  foo   bar\n    baz",
+			"foo bar\nbaz",
+			20,
+			true,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.CollapseSpaces = tc.CollapseSpaces
+		f.TrimIndent = tc.TrimIndent
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapNonSGRSequences(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Cursor positioning keeps its leading zeros:
+		{
+			"\x1B[01;01Hfoo bar",
+			"\x1B[01;01Hfoo\nbar",
+			4,
+		},
+		{
+			"foo\x1B[0;5Hbar baz",
+			"foo\x1B[0;5Hbar\nbaz",
+			7,
+		},
+		// Erasing:
+		{
+			"\x1B[0Kfoo \x1B[2Jbar",
+			"\x1B[0Kfoo\n\x1B[2Jbar",
+			4,
+		},
+		// Cursor movement isn't restarted after line breaks:
+		{
+			"\x1B[31m\x1B[2Afoo bar\x1B[0m",
+			"\x1B[31m\x1B[2Afoo\x1B[0m\n\x1B[31mbar\x1B[0m",
+			4,
+		},
+		// SGR sequences still lose their leading zeros:
+		{
+			"\x1B[5B\x1B[0031mfoo\x1B[0m",
+			"\x1B[5B\x1B[31mfoo\x1B[0m",
+			4,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapIncompleteSequence(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		{"foo\x1b", "foo", 10},
+		{"foo \x1b[3", "foo", 10},
+		{"foo bar \x1b[31", "foo\nbar", 4},
+		{"\x1b[31mfoo\x1b[0m\x1b[", "\x1b[31mfoo\x1b[0m", 10},
+		{"\x1b", "", 10},
+		// Unterminated string sequences:
+		{"hello \x1b]8;;http://x", "hello", 10},
+		{"hello foo\x1b]8;;http://x\x1b", "hello foo", 10},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapLineFunc(t *testing.T) {
+	tt := []struct {
+		Input     string
+		Limit     int
+		Graphemes bool
+		Lines     []string
+		Widths    []int
+	}{
+		{"", 10, false, nil, nil},
+		// Soft and hard line breaks, and the last line on Close:
+		{
+			"the quick brown fox\njumps",
+			10,
+			false,
+			[]string{"the quick", "brown fox", "jumps"},
+			[]int{9, 9, 5},
+		},
+		// No empty line after a trailing newline:
+		{
+			"foo\n\nbar\n",
+			10,
+			false,
+			[]string{"foo", "", "bar"},
+			[]int{3, 0, 3},
+		},
+		// Styled lines:
+		{
+			"\x1B[31mthe quick brown\x1B[0m 你好",
+			10,
+			false,
+			[]string{"\x1B[31mthe quick\x1B[0m", "\x1B[31mbrown\x1B[0m 你好"},
+			[]int{9, 10},
+		},
+		// Limit of zero passes through:
+		{
+			"foo bar\nbaz",
+			0,
+			false,
+			[]string{"foo bar", "baz"},
+			[]int{7, 3},
+		},
+		// Grapheme clusters:
+		{
+			"👨‍👩‍👧 ab",
+			5,
+			true,
+			[]string{"👨‍👩‍👧 ab"},
+			[]int{5},
+		},
+	}
+
+	for i, tc := range tt {
+		var lines []string
+		var widths []int
+
+		f := NewWriterPipe(tc.Limit, WithLineFunc(func(line []byte, width int) {
+			lines = append(lines, string(line))
+			widths = append(widths, width)
+		}), WithGraphemeAware(tc.Graphemes))
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if len(lines) != len(tc.Lines) || len(lines) != f.LineCount() {
+			t.Fatalf("Test %d, expected lines %q, got %q", i, tc.Lines, lines)
+		}
+		for j := range lines {
+			if lines[j] != tc.Lines[j] || widths[j] != tc.Widths[j] {
+				t.Errorf("Test %d, expected line %q of width %d, got %q of width %d", i, tc.Lines[j], tc.Widths[j], lines[j], widths[j])
+			}
+		}
+	}
+}
+
+func TestWordWrapLines(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected []string
+		Limit    int
+	}{
+		{
+			"",
+			nil,
+			10,
+		},
+		{
+			"foo bar baz",
+			[]string{"foo bar", "baz"},
+			7,
+		},
+		// A trailing newline doesn't start another line:
+		{
+			"foo bar\n",
+			[]string{"foo", "bar"},
+			4,
+		},
+		// Blank lines are kept:
+		{
+			"foo\n\nbar\n\n",
+			[]string{"foo", "", "bar", ""},
+			4,
+		},
+		// Escape sequences are never split:
+		{
+			"\x1B]0;foo\nbar\a\x1B[31mfoo bar\x1B[0m",
+			[]string{"\x1B]0;foo\nbar\a\x1B[31mfoo\x1B[0m", "\x1B[31mbar\x1B[0m"},
+			4,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := Lines(tc.Input, tc.Limit)
+		if len(actual) != len(tc.Expected) {
+			t.Errorf("Test %d, expected %q, got %q", i, tc.Expected, actual)
+			continue
+		}
+		for j := range actual {
+			if actual[j] != tc.Expected[j] {
+				t.Errorf("Test %d, expected %q, got %q", i, tc.Expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestWordWrapSetLimit(t *testing.T) {
+	tt := []struct {
+		Input    []string
+		Limits   []int
+		Expected string
+	}{
+		// Growing the limit:
+		{
+			[]string{"foo bar baz ", "foo bar baz"},
+			[]int{7, 20},
+			"foo bar\nbaz foo bar baz",
+		},
+		// Shrinking the limit:
+		{
+			[]string{"foo bar baz ", "foo bar baz"},
+			[]int{20, 7},
+			"foo bar baz\nfoo bar\nbaz",
+		},
+		// The pending word is moved, if the current line exceeds the new
+		// limit:
+		{
+			[]string{"foo bar", " baz"},
+			[]int{10, 5},
+			"foo\nbar\nbaz",
+		},
+		// Disabling the limit:
+		{
+			[]string{"foo bar baz ", "foo bar baz"},
+			[]int{7, 0},
+			"foo bar\nbaz foo bar baz",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(0)
+		for j, in := range tc.Input {
+			f.SetLimit(tc.Limits[j])
+			_, err := f.Write([]byte(in))
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapTrailingNewline(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// A missing line break is added:
+		{
+			"foo bar",
+			"foo\nbar\n",
+			4,
+		},
+		// An existing one isn't duplicated:
+		{
+			"foo bar\n",
+			"foo\nbar\n",
+			4,
+		},
+		{
+			"foo\n\n",
+			"foo\n\n",
+			4,
+		},
+		// Empty input stays empty:
+		{
+			"",
+			"",
+			4,
+		},
+		// Styles are reset before the line break:
+		{
+			"\x1B[31mfoo",
+			"\x1B[31mfoo\x1B[0m\n",
+			4,
+		},
+		// Passing through:
+		{
+			"foo bar",
+			"foo bar\n",
+			0,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.TrailingNewline = true
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapHangingIndent(t *testing.T) {
+	tt := []struct {
+		Input          string
+		Expected       string
+		Limit          int
+		HangingIndent  string
+		PreserveSpaces bool
+	}{
+		// Bullet lists:
+		{
+			"- the quick brown fox jumps over the lazy dog\n- foo bar",
+			"- the quick\n  brown fox\n  jumps\n  over the\n  lazy dog\n- foo bar",
+			11,
+			"  ",
+			false,
+		},
+		// Styled input is restarted after the indent:
+		{
+			"- \x1B[31mfoo bar baz\x1B[0m",
+			"- \x1B[31mfoo\x1B[0m\n  \x1B[31mbar\x1B[0m\n  \x1B[31mbaz\x1B[0m",
+			6,
+			"  ",
+			false,
+		},
+		// Visible indents:
+		{
+			"foo bar baz qux",
+			"foo bar\n> baz\n> qux",
+			7,
+			"> ",
+			false,
+		},
+		// Words wider than the remaining space aren't wrapped again:
+		{
+			"foo foobar",
+			"foo\n  foobar",
+			6,
+			"  ",
+			false,
+		},
+		// Preserved spaces are indented, too:
+		{
+			"foo      bar",
+			"foo   \n>    \n> bar",
+			6,
+			"> ",
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.HangingIndent = tc.HangingIndent
+		f.PreserveSpaces = tc.PreserveSpaces
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapLinePrefix(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Prefix   string
+		Hanging  string
+	}{
+		// Every line gets the prefix, which takes up room:
+		{
+			"The quick brown fox jumps over the lazy dog.",
+			"> The quick\n> brown fox\n> jumps over\n> the lazy\n> dog.",
+			12,
+			"> ",
+			"",
+		},
+		// Explicit line breaks, blank lines and trailing newlines:
+		{
+			"foo bar\n\nbaz\n",
+			"> foo\n> bar\n>\n> baz\n",
+			6,
+			"> ",
+			"",
+		},
+		// Prefixes of the input are not repeated:
+		{
+			"> The quick brown fox\n>\n> jumps over the lazy dog.",
+			"> The quick\n> brown fox\n>\n> jumps over\n> the lazy\n> dog.",
+			12,
+			"> ",
+			"",
+		},
+		// Along with a hanging indent:
+		{
+			"- The quick brown fox",
+			"> - The\n>   quick\n>   brown\n>   fox",
+			9,
+			"> ",
+			"  ",
+		},
+		// Styled text and prefixes:
+		{
+			"\x1B[31mThe quick brown fox\x1B[0m",
+			"\x1B[2m│\x1B[0m \x1B[31mThe quick\x1B[0m\n\x1B[2m│\x1B[0m \x1B[31mbrown fox\x1B[0m",
+			11,
+			"\x1B[2m│\x1B[0m ",
+			"",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.LinePrefix = tc.Prefix
+		f.HangingIndent = tc.Hanging
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapAutoHangingIndent(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Hanging  string
+	}{
+		// Continuations are indented like their line:
+		{
+			"- the quick brown fox\n  - jumps over the lazy dog\n    - and keeps running",
+			"- the quick\nbrown fox\n  - jumps over\n  the lazy dog\n    - and\n    keeps\n    running",
+			14,
+			"",
+		},
+		// The hanging indent is added on top:
+		{
+			"- the quick brown fox\n  - jumps over the lazy dog\n    - and keeps running",
+			"- the quick\n  brown fox\n  - jumps over\n    the lazy\n    dog\n    - and\n      keeps\n      running",
+			14,
+			"  ",
+		},
+		// Unindented lines stay unindented:
+		{
+			"  indented text\nnot indented text",
+			"  indented\n  text\nnot\nindented\ntext",
+			10,
+			"",
+		},
+		// Styled text:
+		{
+			"  \x1B[31mthe quick brown\x1B[0m",
+			"  \x1B[31mthe quick\x1B[0m\n  \x1B[31mbrown\x1B[0m",
+			12,
+			"",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.AutoHangingIndent = true
+		f.HangingIndent = tc.Hanging
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapFillBackground(t *testing.T) {
+	tt := []struct {
+		Input       string
+		Expected    string
+		NoAnsiReset bool
+	}{
+		// Lines without a background aren't padded:
+		{
+			"\x1B[31mfoo bar baz\x1B[0m",
+			"\x1B[31mfoo bar\x1B[0m\n\x1B[31mbaz\x1B[0m",
+			false,
+		},
+		// Backgrounds are extended to the limit:
+		{
+			"\x1B[41mfoo bar baz\x1B[0m",
+			"\x1B[41mfoo bar \x1B[0m\n\x1B[41mbaz\x1B[0m",
+			false,
+		},
+		{
+			"\x1B[48;5;17mfoo\nbar baz qux\x1B[0m",
+			"\x1B[48;5;17mfoo     \x1B[0m\n\x1B[48;5;17mbar baz \x1B[0m\n\x1B[48;5;17mqux\x1B[0m",
+			false,
+		},
+		// Only while the background is active:
+		{
+			"foo \x1B[41mbar\x1B[49m baz qux",
+			"foo \x1B[41mbar\x1B[49m\nbaz qux",
+			false,
+		},
+		{
+			"foo \x1B[41mbar baz\x1B[0m qux",
+			"foo \x1B[41mbar \x1B[0m\n\x1B[41mbaz\x1B[0m qux",
+			false,
+		},
+		// Styles kept open across line breaks:
+		{
+			"\x1B[41mfoo bar baz qux\x1B[0m",
+			"\x1B[41mfoo bar \nbaz qux\x1B[0m",
+			true,
+		},
+		{
+			"\x1B[41mfoo bar baz qux quux\x1B[0m",
+			"\x1B[41mfoo bar \nbaz qux \nquux\x1B[0m",
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(8)
+		f.FillBackground = true
+		f.NoAnsiReset = tc.NoAnsiReset
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapCondition(t *testing.T) {
+	tt := []struct {
+		Input          string
+		Expected       string
+		Limit          int
+		EastAsianWidth bool
+		GraphemeAware  bool
+	}{
+		// Ambiguous characters are narrow:
+		{
+			"αβγ δεζ",
+			"αβγ δεζ",
+			7,
+			false,
+			false,
+		},
+		// Ambiguous characters are wide:
+		{
+			"αβγ δεζ",
+			"αβγ\nδεζ",
+			7,
+			true,
+			false,
+		},
+		{
+			"‘foo’ bar",
+			"‘foo’\nbar",
+			9,
+			true,
+			false,
+		},
+		{
+			"αβγ δεζ",
+			"αβγ\nδεζ",
+			7,
+			true,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Condition = &runewidth.Condition{EastAsianWidth: tc.EastAsianWidth}
+		f.GraphemeAware = tc.GraphemeAware
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapReplaceControl(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Replace  string
+	}{
+		// Control characters are dropped:
+		{
+			"foo\x00bar \x07baz\x0cqux",
+			"foobar\nbazqux",
+			"",
+		},
+		// Or replaced:
+		{
+			"foo\x00bar \x07baz\x0cqux",
+			"foo?bar\n?baz?qux",
+			"?",
+		},
+		{
+			"foo\x07 bar",
+			"foo\x1B[7m^G\x1B[0m\nbar",
+			"\x1B[7m^G\x1B[0m",
+		},
+		// Control characters in replacements are dropped:
+		{
+			"foo\x00bar",
+			"foo<>bar",
+			"<\x00>",
+		},
+		// Newlines, tabs and escape sequences are kept:
+		{
+			"\x1B[31mfoo\tbar\x1B[0m\nbaz",
+			"\x1B[31mfoo\tbar\x1B[0m\nbaz",
+			"?",
+		},
+		// So are string sequences terminated by BEL:
+		{
+			"\x1B]8;;https://example.com\afoo\x1B]8;;\a\x07",
+			"\x1B]8;;https://example.com\afoo\x1B]8;;\a?",
+			"?",
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(8)
+		f.ReplaceControl = true
+		f.ControlReplace = tc.Replace
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapWidthFunc(t *testing.T) {
+	runeCount := func(rune) int { return 1 }
+
+	tt := []struct {
+		Input         string
+		Expected      string
+		Limit         int
+		WidthFunc     func(rune) int
+		GraphemeAware bool
+	}{
+		// Display width:
+		{
+			"你好 世界 foo",
+			"你好\n世界\nfoo",
+			6,
+			nil,
+			false,
+		},
+		// Rune count:
+		{
+			"你好 世界 foo",
+			"你好 世界\nfoo",
+			6,
+			runeCount,
+			false,
+		},
+		// Emoji:
+		{
+			"👍👍 👍👍",
+			"👍👍\n👍👍",
+			4,
+			nil,
+			false,
+		},
+		{
+			"👍👍 👍👍",
+			"👍👍 👍👍",
+			5,
+			runeCount,
+			false,
+		},
+		// Combining characters count as runes:
+		{
+			"éé foo",
+			"éé\nfoo",
+			6,
+			runeCount,
+			false,
+		},
+		// Unless measuring grapheme clusters:
+		{
+			"éé foo",
+			"éé foo",
+			6,
+			runeCount,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.WidthFunc = tc.WidthFunc
+		f.GraphemeAware = tc.GraphemeAware
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapSoftHyphen(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Unused soft hyphens are dropped:
+		{
+			"encyclo\u00ADpedia",
+			"encyclopedia",
+			20,
+		},
+		{
+			"encyclo\u00ADpedia",
+			"encyclopedia",
+			12,
+		},
+		// Breaking at a soft hyphen shows a hyphen:
+		{
+			"encyclo\u00ADpedia",
+			"encyclo-\npedia",
+			8,
+		},
+		// The hyphen has to fit, too:
+		{
+			"encyclo\u00ADpedia",
+			"encyclopedia",
+			7,
+		},
+		{
+			"foo encyclo\u00ADpedia",
+			"foo encyclo-\npedia",
+			12,
+		},
+		{
+			"foo encyclo\u00ADpedia",
+			"foo\nencyclo-\npedia",
+			10,
+		},
+		// The last soft hyphen fitting is used:
+		{
+			"en\u00ADcy\u00ADclo\u00ADpe\u00ADdia",
+			"encyclo-\npedia",
+			9,
+		},
+		// Styled words:
+		{
+			"\x1B[31mencyclo\u00ADpedia\x1B[0m",
+			"\x1B[31mencyclo-\x1B[0m\n\x1B[31mpedia\x1B[0m",
+			8,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapZeroWidthSpace(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Unused zero-width spaces are dropped:
+		{
+			"one\u200btwo\u200bthree",
+			"onetwothree",
+			20,
+		},
+		{
+			"one\u200btwo\u200bthree",
+			"onetwothree",
+			11,
+		},
+		// Breaking at a zero-width space adds nothing else:
+		{
+			"one\u200btwo\u200bthree",
+			"onetwo\nthree",
+			8,
+		},
+		{
+			"one\u200btwo\u200bthree",
+			"one\ntwo\nthree",
+			5,
+		},
+		{
+			"one\u200btwo\u200bthree",
+			"one\ntwo\nthree",
+			3,
+		},
+		// Next to spaces:
+		{
+			"one \u200btwo\u200b three",
+			"one two\nthree",
+			8,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapBidiControls(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Marks don't take up any room:
+		{
+			"\u200fשלום עולם\u200f foo",
+			"\u200fשלום עולם\u200f\nfoo",
+			10,
+		},
+		{
+			"foo \u200ebar baz",
+			"foo \u200ebar\nbaz",
+			7,
+		},
+		{
+			"\u2066abc\u2069 defg",
+			"\u2066abc\u2069 defg",
+			8,
+		},
+		// Nor do they split words:
+		{
+			"abc\u202bdef ghi",
+			"abc\u202bdef\nghi",
+			7,
+		},
+		{
+			"abc\u202bdef ghi",
+			"abc\u202bdef ghi",
+			10,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapConsumingBreakpoints(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		// Kept unless the line gets broken at them:
+		{
+			"foo|bar|baz",
+			"foo|bar|baz",
+			20,
+		},
+		{
+			"foo|bar|baz",
+			"foo|bar\nbaz",
+			8,
+		},
+		{
+			"foo|bar|baz",
+			"foo|bar\nbaz",
+			7,
+		},
+		{
+			"foo|bar|baz",
+			"foo\nbar\nbaz",
+			4,
+		},
+		// The dropped breakpoint doesn't need to fit:
+		{
+			"foo|bar|baz",
+			"foo\nbar\nbaz",
+			3,
+		},
+		// Along with spaces:
+		{
+			"one|two three|four",
+			"one|two\nthree\nfour",
+			8,
+		},
+		// Styled:
+		{
+			"\x1B[31mfoo|bar\x1B[0m|baz",
+			"\x1B[31mfoo|bar\x1B[0m\nbaz",
+			8,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.ConsumingBreakpoints = []rune{'|'}
+		_, _ = f.Write([]byte(tc.Input))
+		_ = f.Close()
+
+		if actual := f.String(); actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapOverflow(t *testing.T) {
+	const (
+		hash   = "0123456789abcdef0123456789abcdef01234567"
+		hyphen = "0123456789-abcdef-0123456789abcdef-01234"
+	)
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Mode     OverflowMode
+	}{
+		{
+			"foo " + hash + " bar",
+			"foo\n" + hash + "\nbar",
+			15,
+			OverflowLeave,
+		},
+		{
+			"foo " + hash + " bar",
+			"foo 0123456789a\nbcdef0123456789\nabcdef01234567\nbar",
+			15,
+			OverflowBreak,
+		},
+		{
+			"foo " + hash + " bar",
+			"foo\n0123456789abcde\nf0123456789abcd\nef01234567 bar",
+			15,
+			OverflowBreakAtBreakpoints,
+		},
+		{
+			"foo " + hyphen + " bar",
+			"foo 0123456789-\nabcdef-\n0123456789abcdef-\n01234 bar",
+			15,
+			OverflowLeave,
+		},
+		{
+			"foo " + hyphen + " bar",
+			"foo 0123456789-\nabcdef-01234567\n89abcdef-01234\nbar",
+			15,
+			OverflowBreak,
+		},
+		{
+			"foo " + hyphen + " bar",
+			"foo 0123456789-\nabcdef-\n0123456789abcde\nf-01234 bar",
+			15,
+			OverflowBreakAtBreakpoints,
+		},
+		// Words fitting the limit are wrapped as a whole:
+		{
+			"foo bar baz",
+			"foo\nbar\nbaz",
+			5,
+			OverflowBreak,
+		},
+		// ANSI sequences:
+		{
+			"ab \x1B[31mfoobarbaz\x1B[0m",
+			"ab \x1B[31mfo\x1B[0m\n\x1B[31mobarb\x1B[0m\n\x1B[31maz\x1B[0m",
+			5,
+			OverflowBreak,
+		},
+		// Double-width runes:
+		{
+			"a 你好世界",
+			"a 你\n好世\n界",
+			5,
+			OverflowBreak,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Overflow = tc.Mode
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapOverflowStreaming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+
+	const limit = 80
+	chunk := []byte(strings.Repeat("a", 4096))
+
+	f := NewWriter(limit)
+	f.Overflow = OverflowBreak
+	for i := 0; i < 10<<20; i += len(chunk) {
+		_, err := f.Write(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.word.Len() > limit {
+			t.Fatalf("expected the pending word to stay within the limit, got %d bytes", f.word.Len())
+		}
+	}
+	f.Close()
+
+	if f.MaxWidth() != limit {
+		t.Errorf("expected the widest line to be %d wide, got %d", limit, f.MaxWidth())
+	}
+	if n := (10<<20 + limit - 1) / limit; f.LineCount() != n {
+		t.Errorf("expected %d lines, got %d", n, f.LineCount())
+	}
+}
+
+func TestWordWrapContinuationSpaces(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		{
+			"word1      word2",
+			"word1\nword2",
+			8,
+		},
+		{
+			"word1      word2",
+			"word1      word2",
+			16,
+		},
+		{
+			"word1      word2",
+			"word1\nword2",
+			15,
+		},
+		// Spaces before a breakpoint:
+		{
+			"word1      -word2",
+			"word1\n-word2",
+			8,
+		},
+		{
+			"word1      -word2",
+			"word1      -\nword2",
+			12,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestWordWrapSequenceMatchers(t *testing.T) {
+	const image = "\x1B_Gf=100,a=T;AAAA\x1B\\"
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Width    int
+	}{
+		// Unknown sequences are zero-width:
+		{
+			"foo " + image + " bar",
+			"foo " + image + " bar",
+			8,
+			-1,
+		},
+		{
+			"foo " + image + " bar",
+			"foo " + image + " bar",
+			8,
+			0,
+		},
+		{
+			"foo " + image + " bar",
+			"foo\n" + image + "\nbar",
+			8,
+			5,
+		},
+		{
+			"foo " + image + " bar",
+			"foo " + image + "\nbar",
+			9,
+			5,
+		},
+		// Sequences are part of the surrounding word:
+		{
+			"foo ab" + image + "cd",
+			"foo\nab" + image + "cd",
+			8,
+			5,
+		},
+		{
+			"foo ab" + image + "cd",
+			"foo ab" + image + "cd",
+			13,
+			5,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		if tc.Width >= 0 {
+			f.SequenceMatchers = []SequenceMatcher{SequencePrefix("\x1B_G", tc.Width)}
+		}
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapURLBreakpoints(t *testing.T) {
+	url := "https://github.com/muesli/reflow/blob/master/wordwrap/wordwrap.go"
+
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		{
+			"see " + url + " for details",
+			"see https://github.\ncom/muesli/reflow/\nblob/master/\nwordwrap/wordwrap.go\nfor details",
+			20,
+		},
+		{
+			"see " + url + " for details",
+			"see https://github.com/muesli/\nreflow/blob/master/wordwrap/\nwordwrap.go for details",
+			30,
+		},
+		{
+			url,
+			"https://\ngithub.\ncom/\nmuesli/\nreflow/\nblob/\nmaster/\nwordwrap/\nwordwrap.\ngo",
+			10,
+		},
+		// Breakpoints don't exceed the limit:
+		{
+			"foo bar-baz",
+			"foo\nbar-baz",
+			7,
+		},
+		// Styles are restarted on the line the breakpoint moved to:
+		{
+			"\x1B[31maaaa b-c\x1B[0m",
+			"\x1B[31maaaa\x1B[0m\n\x1B[31mb-c\x1B[0m",
+			6,
+		},
+		{
+			"\x1B[31maaaaa -\x1B[0m",
+			"\x1B[31maaaaa\x1B[0m\n\x1B[31m-\x1B[0m",
+			6,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriterPipe(tc.Limit, WithBreakpoints(URLBreakpoints))
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+		for _, l := range f.Lines() {
+			if n := ansi.PrintableRuneWidth(l); n > tc.Limit {
+				t.Errorf("Test %d, line %q exceeds the limit", i, l)
+			}
+		}
+	}
+}
+
+func TestWordWrapBreakBefore(t *testing.T) {
+	tt := []struct {
+		Input       string
+		Expected    string
+		Limit       int
+		Breakpoints []rune
+		BreakBefore []rune
+	}{
+		// Break after '/':
+		{
+			"foo/bar(baz)",
+			"foo/\nbar(baz)",
+			8,
+			[]rune{'/'},
+			nil,
+		},
+		// Break before '(':
+		{
+			"foo/bar(baz)",
+			"foo/bar\n(baz)",
+			8,
+			[]rune{'/'},
+			[]rune{'('},
+		},
+		{
+			"foo/bar(baz)",
+			"foo/\nbar\n(baz)",
+			5,
+			[]rune{'/'},
+			[]rune{'('},
+		},
+		// The rune isn't separated from the following word:
+		{
+			"call foo(bar)",
+			"call\nfoo\n(bar)",
+			5,
+			nil,
+			[]rune{'('},
+		},
+		// Spaces are kept before the rune:
+		{
+			"a (b)",
+			"a (b)",
+			5,
+			nil,
+			[]rune{'('},
+		},
+		{
+			"\x1B[31mfoo(bar)\x1B[0m",
+			"\x1B[31mfoo\x1B[0m\n\x1B[31m(bar)\x1B[0m",
+			6,
+			nil,
+			[]rune{'('},
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Breakpoints = tc.Breakpoints
+		f.BreakBefore = tc.BreakBefore
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestHeight(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Limit    int
+		Expected int
+	}{
+		{"", 10, 0},
+		{"foo", 10, 1},
+		{"foo bar baz", 3, 3},
+		{"foo bar baz", 7, 2},
+		{"foo\n\nbar\n", 10, 3},
+		{"foo-bar-baz", 4, 3},
+		{"\x1B[31mfoo bar\x1B[0m baz", 3, 3},
+		{"你好世界 foo", 4, 2},
+		{"foobarbaz qux", 4, 2},
+		{"foo bar\nbaz", 0, 2},
+	}
+
+	for i, tc := range tt {
+		h := Height(tc.Input, tc.Limit)
+		if h != tc.Expected {
+			t.Errorf("Test %d, expected %d lines, got %d", i, tc.Expected, h)
+		}
+		if n := len(Lines(tc.Input, tc.Limit)); h != n {
+			t.Errorf("Test %d, expected the height to match the %d lines, got %d", i, n, h)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog.\n\n" +
+		"\x1B[31mLorem ipsum dolor sit amet,\x1B[0m consectetur adipiscing elit, " +
+		"sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.\n" +
+		"你好世界 supercalifragilisticexpialidocious\n"
+
+	tt := []struct {
+		Input string
+		Limit int
+		Opts  []Option
+	}{
+		{"", 10, nil},
+		{"foo", 10, nil},
+		{text, 10, nil},
+		{text, 20, nil},
+		{text, 0, nil},
+		{text, 12, []Option{WithHardWrap(true)}},
+		{text, 12, []Option{WithOverflow(OverflowBreak)}},
+		{text, 16, []Option{WithJustify(true), WithHangingIndent("  ")}},
+		{text, 16, []Option{WithParagraphMode(true), WithTrailingNewline(true)}},
+		{text, 16, []Option{WithLineBreakSuffix(" ↩"), WithFillBackground(true)}},
+	}
+
+	for i, tc := range tt {
+		f := NewWriterPipe(tc.Limit, tc.Opts...)
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		var width int
+		for _, l := range f.Lines() {
+			if n := ansi.PrintableRuneWidth(l); n > width {
+				width = n
+			}
+		}
+
+		w, h := Measure(tc.Input, tc.Limit, tc.Opts...)
+		if w != width || h != len(f.Lines()) {
+			t.Errorf("Test %d, expected %dx%d, got %dx%d", i, width, len(f.Lines()), w, h)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tt := []struct {
+		Input     string
+		Expected  string
+		Limit     int
+		MaxLines  int
+		Indicator string
+	}{
+		// Fitting:
+		{
+			"foo bar\nbaz",
+			"foo bar\nbaz",
+			10,
+			2,
+			"…",
+		},
+		{
+			"",
+			"",
+			10,
+			0,
+			"…",
+		},
+		// The indicator is added if it fits:
+		{
+			"The quick brown fox jumps over the lazy dog.",
+			"The quick\nbrown fox…",
+			10,
+			2,
+			"…",
+		},
+		{
+			"foo bar\nbaz",
+			"foo bar…",
+			10,
+			1,
+			"…",
+		},
+		// Otherwise it replaces the last words:
+		{
+			"The quick brown fox jumps over the lazy dog.",
+			"The…",
+			9,
+			1,
+			"…",
+		},
+		{
+			"The quick brown fox jumps over the lazy dog.",
+			"The quick\nbrown…",
+			9,
+			2,
+			"…",
+		},
+		// Words exceeding the limit get cut:
+		{
+			"supercalifragilistic expialidocious",
+			"supercali…",
+			10,
+			1,
+			"…",
+		},
+		// Styling is reset:
+		{
+			"\x1B[31mThe quick brown\x1B[0m fox jumps",
+			"\x1B[31mThe\x1B[0m…",
+			9,
+			1,
+			"…",
+		},
+		{
+			"\x1B[31mThe quick brown\x1B[0m fox jumps",
+			"\x1B[31mThe quick\x1B[0m\n\x1B[31mbrown\x1B[0m fox…",
+			10,
+			2,
+			"…",
+		},
+		// The indicator is cut if it exceeds the limit:
+		{
+			"foo bar\nbaz",
+			"..",
+			2,
+			1,
+			"...",
+		},
+		// Trailing newlines are kept:
+		{
+			"foo bar\n",
+			"foo bar\n",
+			10,
+			1,
+			"…",
+		},
+		// Nothing:
+		{
+			"foo bar",
+			"",
+			10,
+			0,
+			"…",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := Clamp(tc.Input, tc.Limit, tc.MaxLines, tc.Indicator)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+		for _, l := range strings.Split(actual, "\n") {
+			if w := ansi.PrintableRuneWidth(l); w > tc.Limit {
+				t.Errorf("Test %d, line %q is %d cells wide", i, l, w)
+			}
+		}
+	}
+}
+
+func TestWordWrapWordBoundaryFunc(t *testing.T) {
+	spaces := func(prev, cur rune) bool {
+		return unicode.IsSpace(cur)
+	}
+	camelCase := func(prev, cur rune) bool {
+		return unicode.IsSpace(cur) || unicode.IsLower(prev) && unicode.IsUpper(cur)
+	}
+	units := func(prev, cur rune) bool {
+		return unicode.IsSpace(cur) && !unicode.IsDigit(prev)
+	}
+
+	tt := []struct {
+		Input            string
+		Expected         string
+		Limit            int
+		WordBoundaryFunc func(prev, cur rune) bool
+	}{
+		// Paths get broken at the breakpoints:
+		{
+			"see /usr/local-lib/foo now",
+			"see /usr/\nlocal-lib/\nfoo now",
+			10,
+			nil,
+		},
+		// Unless only spaces are word boundaries:
+		{
+			"see /usr/local-lib/foo now",
+			"see\n/usr/local-lib/foo\nnow",
+			10,
+			spaces,
+		},
+		{
+			"parseHTTPResponseHeader",
+			"parse\nHTTPResponse\nHeader",
+			12,
+			camelCase,
+		},
+		{
+			"parse the HTTPResponse",
+			"parse the\nHTTPResponse",
+			12,
+			camelCase,
+		},
+		// Spaces without a boundary are part of the word:
+		{
+			"run 10 km now",
+			"run\n10 km\nnow",
+			8,
+			units,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.Breakpoints = []rune{'-', '/'}
+		f.WordBoundaryFunc = tc.WordBoundaryFunc
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+		f.Close()
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
+
+func TestWordWrapNormalizeAnsi(t *testing.T) {
+	tt := []struct {
+		Input      string
+		Normalized string
+		Verbatim   string
+		Limit      int
+	}{
+		{
+			"\x1B[0031;0000mfoo bar",
+			"\x1B[31;0mfoo\nbar",
+			"\x1B[0031;0000mfoo\nbar",
+			4,
+		},
+		{
+			"\x1B[034mblue\x1B[33;0;031mred\x1B[0m",
+			"\x1B[34mblue\x1B[33;0m\x1B[31mred\x1B[0m",
+			"\x1B[034mblue\x1B[33;0;031mred\x1B[0m",
+			10,
+		},
+		// Sequences are still restarted after line breaks:
+		{
+			"\x1B[0031mfoo bar\x1B[0000m baz",
+			"\x1B[31mfoo\x1B[0m\n\x1B[31mbar\x1B[0m\nbaz",
+			"\x1B[0031mfoo\x1B[0m\n\x1B[0031mbar\x1B[0000m\nbaz",
+			4,
+		},
+		{
+			"\x1B[0031mfoo\x1B[00m bar",
+			"\x1B[31mfoo\x1B[0m\nbar",
+			"\x1B[0031mfoo\x1B[00m\nbar",
+			4,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriterPipe(tc.Limit)
+		_, _ = f.Write([]byte(tc.Input))
+		_ = f.Close()
+		if actual := f.String(); actual != tc.Normalized {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Normalized, actual)
+		}
+
+		f = NewWriterPipe(tc.Limit, WithNormalizeAnsi(false))
+		_, _ = f.Write([]byte(tc.Input))
+		_ = f.Close()
+		if actual := f.String(); actual != tc.Verbatim {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Verbatim, actual)
+		}
+	}
+}
+
+func TestWordWrapSkipNoopAnsi(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+	}{
+		{
+			"\x1B[31mfoo\x1B[0m bar baz",
+			"\x1B[31mfoo\x1B[0m\nbar\nbaz",
+		},
+		// Empty parameters reset, too:
+		{
+			"\x1B[31mfoo\x1B[m bar baz",
+			"\x1B[31mfoo\x1B[m\nbar\nbaz",
+		},
+		{
+			"\x1B[31mfoo\x1B[;m bar baz",
+			"\x1B[31mfoo\x1B[;m\nbar\nbaz",
+		},
+		// Attributes turned off again:
+		{
+			"\x1B[1;31mfoo\x1B[22;39m bar baz",
+			"\x1B[1;31mfoo\x1B[22;39m\nbar\nbaz",
+		},
+		// Active styles are still restarted:
+		{
+			"\x1B[1;31mfoo\x1B[22m bar",
+			"\x1B[1;31mfoo\x1B[22m\x1B[0m\n\x1B[1;31m\x1B[22mbar",
+		},
+		// Unknown attributes are restarted, too:
+		{
+			"\x1B[53mfoo\x1B[55m bar",
+			"\x1B[53mfoo\x1B[55m\x1B[0m\n\x1B[53m\x1B[55mbar",
+		},
+		{
+			"\x1B[4mfoo\x1B[4:0m bar baz",
+			"\x1B[4mfoo\x1B[4:0m\nbar\nbaz",
+		},
+		{
+			"\x1B[26mfoo\x1B[22m bar",
+			"\x1B[26mfoo\x1B[22m\x1B[0m\n\x1B[26m\x1B[22mbar",
+		},
+	}
+
+	for i, tc := range tt {
+		actual := String(tc.Input, 4)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}
+
+func TestBreakPositions(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Limit    int
+		Expected []int
+	}{
+		{"", 5, nil},
+		{"foo", 5, nil},
+		{"foo bar baz", 7, []int{8}},
+		{"foo bar baz", 3, []int{4, 8}},
+		// Spaces dropped at the break:
+		{"foo    bar", 5, []int{7}},
+		// Line breaks of the input aren't reported:
+		{"foo\nbar baz", 5, []int{8}},
+		// Breakpoints:
+		{"foo-bar", 5, []int{4}},
+		// Escape sequences count as runes, but are zero-width:
+		{"\x1B[31mfoo bar\x1B[0m", 3, []int{9}},
+		{"\x1B]8;;https://example.com\x1B\\foo\x1B]8;;\x1B\\ bar", 5, []int{37}},
+		// Double-width runes:
+		{"你好 世界", 4, []int{3}},
+		{"你好 世界 foo", 7, []int{3, 6}},
+		// Soft hyphens:
+		{"encyclo\u00ADpedia", 8, []int{8}},
+	}
+
+	for i, tc := range tt {
+		positions := BreakPositions(tc.Input, tc.Limit)
+		if len(positions) != len(tc.Expected) {
+			t.Errorf("Test %d, expected %v, got %v", i, tc.Expected, positions)
+			continue
+		}
+		for j := range positions {
+			if positions[j] != tc.Expected[j] {
+				t.Errorf("Test %d, expected %v, got %v", i, tc.Expected, positions)
+				break
+			}
+		}
+
+		// the positions match the wrapped lines
+		runes := []rune(tc.Input)
+		for _, p := range positions {
+			if p <= 0 || p >= len(runes) || runes[p-1] == '\n' {
+				t.Errorf("Test %d, invalid break position %d", i, p)
+			}
+		}
+	}
+}
+
+func TestStringWithCursor(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+		Row, Col int
+	}{
+		{"", "", 10, 0, 0},
+		{"foo", "foo", 10, 0, 3},
+		// Trailing content:
+		{"foo bar baz", "foo bar\nbaz", 7, 1, 3},
+		{"foo bar baz ", "foo bar\nbaz", 7, 1, 3},
+		// Trailing line break:
+		{"foo bar\n", "foo bar\n", 10, 1, 0},
+		{"foo bar baz\n\n", "foo bar\nbaz\n\n", 7, 3, 0},
+		// Wide characters:
+		{"foo 你好", "foo\n你好", 5, 1, 4},
+		// Styled:
+		{"\x1B[31mfoo bar\x1B[0m", "\x1B[31mfoo\x1B[0m\n\x1B[31mbar\x1B[0m", 4, 1, 3},
+	}
+
+	for i, tc := range tt {
+		actual, row, col := StringWithCursor(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+		if row != tc.Row || col != tc.Col {
+			t.Errorf("Test %d, expected the cursor at %d:%d, got %d:%d", i, tc.Row, tc.Col, row, col)
+		}
+	}
+}
+
+func TestStringWithInsertions(t *testing.T) {
+	tt := []struct {
+		Input      string
+		Limit      int
+		Insertions []Insertion
+	}{
+		{"foo", 5, nil},
+		{"foo bar baz", 7, []Insertion{{7, 1, "\n"}}},
+		{"foo   bar", 5, []Insertion{{3, 3, "\n"}}},
+		{"foo\nbar baz", 5, []Insertion{{7, 1, "\n"}}},
+		// Styles get reset and restarted:
+		{
+			"\x1B[31mfoo bar\x1B[0m",
+			3,
+			[]Insertion{{8, 1, "\x1B[0m\n\x1B[31m"}},
+		},
+		// Leading zeros get removed:
+		{
+			"\x1B[031mfoo\x1B[0m",
+			5,
+			[]Insertion{{0, 6, "\x1B[31m"}},
+		},
+		{"你好 世界", 4, []Insertion{{6, 1, "\n"}}},
+		// Soft hyphens:
+		{"encyclo\u00ADpedia", 8, []Insertion{{7, 2, "-\n"}}},
+		{"encyclo\u00ADpedia", 20, []Insertion{{7, 2, ""}}},
+	}
+
+	for i, tc := range tt {
+		wrapped, insertions := StringWithInsertions(tc.Input, tc.Limit)
+		if wrapped != String(tc.Input, tc.Limit) {
+			t.Errorf("Test %d, expected the wrapped string %q, got %q", i, String(tc.Input, tc.Limit), wrapped)
+		}
+
+		if len(insertions) != len(tc.Insertions) {
+			t.Errorf("Test %d, expected %v, got %v", i, tc.Insertions, insertions)
+		} else {
+			for j := range insertions {
+				if insertions[j] != tc.Insertions[j] {
+					t.Errorf("Test %d, expected %v, got %v", i, tc.Insertions, insertions)
+					break
+				}
+			}
+		}
+
+		// applying the insertions reconstructs the wrapped string
+		var b strings.Builder
+		var offset int
+		for _, ins := range insertions {
+			b.WriteString(tc.Input[offset:ins.Offset])
+			b.WriteString(ins.Text)
+			offset = ins.Offset + ins.Removed
+		}
+		b.WriteString(tc.Input[offset:])
+		if b.String() != wrapped {
+			t.Errorf("Test %d, expected the insertions to result in %q, got %q", i, wrapped, b.String())
+		}
+
+		// bytes which weren't removed are mapped onto the same bytes
+	next:
+		for o := 0; o < len(tc.Input); o++ {
+			for _, ins := range insertions {
+				if o >= ins.Offset && o < ins.Offset+ins.Removed {
+					continue next
+				}
+			}
+			if m := MapOffset(insertions, o); wrapped[m] != tc.Input[o] {
+				t.Errorf("Test %d, offset %d got mapped to %d: %q", i, o, m, wrapped[m:])
+			}
+		}
+	}
+}
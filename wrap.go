@@ -0,0 +1,24 @@
+package reflow
+
+import (
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// Wrap word-wraps s at the given limit, the way most text should be wrapped:
+// lines are broken at spaces, which are dropped at the breaks, and after
+// hyphens. Words wider than the limit, such as URLs or hashes, are broken into
+// pieces filling whole lines, with the following words continuing on the line
+// of the last piece. The line breaks of s are kept and escape sequences don't
+// take up any width. A limit below 1 leaves s as it is.
+func Wrap(s string, limit int) string {
+	if limit < 1 {
+		return s
+	}
+
+	f := wordwrap.NewWriter(limit)
+	f.BreakLongWords = true
+	_, _ = f.Write([]byte(s))
+	_ = f.Close()
+
+	return f.String()
+}
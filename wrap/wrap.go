@@ -20,6 +20,7 @@ type Wrap struct {
 	KeepNewlines  bool
 	PreserveSpace bool
 	TabWidth      int
+	GraphemeAware bool // never break inside grapheme clusters, such as emoji or combining sequences
 
 	buf             *bytes.Buffer
 	lineLen         int
@@ -72,46 +73,71 @@ func (w *Wrap) Write(b []byte) (int, error) {
 	}
 
 	width := ansi.PrintableRuneWidth(s)
+	if w.GraphemeAware {
+		width = ansi.PrintableGraphemeWidth(s)
+	}
 
 	if w.Limit <= 0 || w.lineLen+width <= w.Limit {
 		w.lineLen += width
 		return w.buf.Write(b)
 	}
 
-	for _, c := range s {
-		if c == ansi.Marker {
-			w.ansi = true
-		} else if w.ansi {
-			if ansi.IsTerminator(c) {
-				w.ansi = false
-			}
-		} else if inGroup(w.Newline, c) {
-			w.addNewLine()
-			w.forcefulNewline = false
-			continue
-		} else {
-			width := runewidth.RuneWidth(c)
+	if !w.GraphemeAware {
+		for _, c := range s {
+			w.process(c, string(c), runewidth.RuneWidth(c))
+		}
+		return len(b), nil
+	}
 
-			if w.lineLen+width > w.Limit {
-				w.addNewLine()
-				w.forcefulNewline = true
+	ansi.Graphemes(s, runewidth.RuneWidth, func(cluster string, runes []rune, width int) {
+		if len(runes) == 1 || w.ansi || runes[0] == ansi.Marker ||
+			unicode.IsControl(runes[0]) || inGroup(w.Newline, runes[0]) {
+			// single runes, clusters glued to an ANSI sequence and ones
+			// starting with a control character, like "\r\n", are
+			// processed rune by rune
+			for _, c := range runes {
+				w.process(c, string(c), runewidth.RuneWidth(c))
 			}
+			return
+		}
 
-			if w.lineLen == 0 {
-				if w.forcefulNewline && !w.PreserveSpace && unicode.IsSpace(c) {
-					continue
-				}
-			} else {
-				w.forcefulNewline = false
-			}
+		w.process(runes[0], cluster, width)
+	})
 
-			w.lineLen += width
+	return len(b), nil
+}
+
+// process handles a single character of the input. c is its first rune, while
+// cluster holds all of its runes and width its printable width.
+func (w *Wrap) process(c rune, cluster string, width int) {
+	if c == ansi.Marker {
+		w.ansi = true
+	} else if w.ansi {
+		if ansi.IsTerminator(c) {
+			w.ansi = false
+		}
+	} else if inGroup(w.Newline, c) {
+		w.addNewLine()
+		w.forcefulNewline = false
+		return
+	} else {
+		if w.lineLen+width > w.Limit {
+			w.addNewLine()
+			w.forcefulNewline = true
+		}
+
+		if w.lineLen == 0 {
+			if w.forcefulNewline && !w.PreserveSpace && unicode.IsSpace(c) {
+				return
+			}
+		} else {
+			w.forcefulNewline = false
 		}
 
-		_, _ = w.buf.WriteRune(c)
+		w.lineLen += width
 	}
 
-	return len(b), nil
+	_, _ = w.buf.WriteString(cluster)
 }
 
 // Bytes returns the wrapped result as a byte slice.
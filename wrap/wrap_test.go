@@ -138,3 +138,82 @@ func TestWrapString(t *testing.T) {
 		t.Errorf("expected:\n\n`%s`\n\nActual Output:\n\n`%s`", expected, actual)
 	}
 }
+
+func TestWrapGraphemes(t *testing.T) {
+	tt := []struct {
+		Input         string
+		Expected      string
+		Limit         int
+		GraphemeAware bool
+	}{
+		// Emoji sequences get split between their runes:
+		{
+			"a👩\u200d💻",
+			"a👩\u200d\n💻",
+			4,
+			false,
+		},
+		// Unless they're wrapped as a whole:
+		{
+			"a👩\u200d💻",
+			"a👩\u200d💻",
+			4,
+			true,
+		},
+		// Leaving the line one column under the limit:
+		{
+			"ab👩\u200d💻",
+			"ab\n👩\u200d💻",
+			3,
+			true,
+		},
+		// Combining characters stay with their base:
+		{
+			"foe\u0301bar",
+			"foe\u0301\nbar",
+			3,
+			true,
+		},
+		// Flags are wide:
+		{
+			"ab🇩🇪🇫🇷x",
+			"ab🇩🇪\n🇫🇷x",
+			4,
+			true,
+		},
+		{
+			"🇩🇪🇫🇷",
+			"🇩🇪🇫🇷",
+			4,
+			true,
+		},
+		// "\r\n" is a single cluster, but still breaks the line:
+		{
+			"abc\r\ndefg",
+			"abc\r\ndefg",
+			4,
+			true,
+		},
+		// Styled clusters:
+		{
+			"\x1B[31mab👩\u200d💻\x1B[0m",
+			"\x1B[31mab\n👩\u200d💻\x1B[0m",
+			3,
+			true,
+		},
+	}
+
+	for i, tc := range tt {
+		f := NewWriter(tc.Limit)
+		f.GraphemeAware = tc.GraphemeAware
+
+		_, err := f.Write([]byte(tc.Input))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if f.String() != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, f.String())
+		}
+	}
+}
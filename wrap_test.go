@@ -0,0 +1,66 @@
+package reflow
+
+import (
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tt := []struct {
+		Input    string
+		Expected string
+		Limit    int
+	}{
+		{
+			"The quick brown fox jumps over the lazy dog.",
+			"The quick\nbrown fox\njumps over\nthe lazy\ndog.",
+			10,
+		},
+		// Long tokens are broken:
+		{
+			"See https://example.com/a/very/long/path for details.",
+			"See\nhttps://ex\nample.com/\na/very/lon\ng/path for\ndetails.",
+			10,
+		},
+		{
+			"jumps over 0123456789abcdef0123 dogs",
+			"jumps over\n0123456789\nabcdef0123\ndogs",
+			10,
+		},
+		// Line breaks are kept:
+		{
+			"The quick brown fox\n\njumps",
+			"The quick\nbrown fox\n\njumps",
+			10,
+		},
+		// Hyphens:
+		{
+			"well-known self-explanatory",
+			"well-known\nself-\nexplanator\ny",
+			10,
+		},
+		// Styled:
+		{
+			"\x1B[31mabcdefghijkl\x1B[0m mn",
+			"\x1B[31mabcdefghij\x1B[0m\n\x1B[31mkl\x1B[0m mn",
+			10,
+		},
+		// No limit:
+		{
+			"The quick brown fox",
+			"The quick brown fox",
+			0,
+		},
+		{
+			"The quick brown fox",
+			"The quick brown fox",
+			-1,
+		},
+	}
+
+	for i, tc := range tt {
+		actual := Wrap(tc.Input, tc.Limit)
+		if actual != tc.Expected {
+			t.Errorf("Test %d, expected:\n\n`%q`\n\nActual Output:\n\n`%q`", i, tc.Expected, actual)
+		}
+	}
+}